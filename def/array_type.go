@@ -11,16 +11,25 @@ type arrayType struct {
 
 	resolvedPointsAtType TypeDefiner
 	lenSpec              string
-	// resolvedLenSpec      ValueDefiner
+	resolvedLenSpec      ValueDefiner
 }
 
 func (t *arrayType) Category() TypeCategory { return CatArray }
 
 func (t *arrayType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
+	// A constant length, like VK_MAX_EXTENSION_NAME_SIZE, is printed with the value's public name
+	t.resolvedLenSpec = vr[t.lenSpec]
 
 	return t.resolvedPointsAtType.Resolve(tr, vr)
 }
 
+func (t *arrayType) lenName() string {
+	if t.resolvedLenSpec != nil {
+		return t.resolvedLenSpec.PublicName()
+	}
+	return trimVk(t.lenSpec)
+}
+
 func (t *arrayType) IsIdenticalPublicAndInternal() bool {
 	// if this is a void pointer or if the underlying types are identical
 	return t.resolvedPointsAtType.InternalName() == "!none" ||
@@ -34,12 +43,12 @@ func (t *arrayType) PublicName() string {
 	if t.resolvedPointsAtType.PublicName() == "byte" {
 		return "string"
 	}
-	return fmt.Sprintf("[%s]%s", trimVk(t.lenSpec), t.resolvedPointsAtType.PublicName())
+	return fmt.Sprintf("[%s]%s", t.lenName(), t.resolvedPointsAtType.PublicName())
 }
 
 func (t *arrayType) InternalName() string {
 
-	return fmt.Sprintf("[%s]%s", trimVk(t.lenSpec), t.resolvedPointsAtType.InternalName())
+	return fmt.Sprintf("[%s]%s", t.lenName(), t.resolvedPointsAtType.InternalName())
 }
 
 func (t *arrayType) TranslateToInternal(inputVar string) string {
@@ -106,6 +106,7 @@ func ReadBaseTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, _ ValueRegistry,
 func NewBaseTypeFromXML(node *xmlquery.Node) TypeDefiner {
 	rval := baseType{}
 	rval.registryName = xmlquery.FindOne(node, "name").InnerText()
+	rval.comment = node.SelectAttr("comment")
 
 	typeNode := xmlquery.FindOne(node, "type")
 	if typeNode == nil {
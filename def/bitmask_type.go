@@ -38,6 +38,12 @@ func (t *bitmaskType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 func (t *bitmaskType) PrintPublicDeclaration(w io.Writer) {
 	t.internalType.PrintPublicDeclaration(w)
 
+	if !t.IsAlias() {
+		// Receiver is the flags type itself, so 64-bit flags keep their Flags64 underlying type
+		fmt.Fprintf(w, "// Has returns true if all of bits are set in f\n")
+		fmt.Fprintf(w, "func (f %s) Has(bits %s) bool { return f&bits == bits }\n\n", t.PublicName(), t.PublicName())
+	}
+
 	sort.Sort(ByValue(t.values))
 
 	if len(t.values) > 0 {
@@ -78,6 +84,7 @@ func ReadBitmaskTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, vr ValueRegist
 
 func NewBitmaskTypeFromXML(node *xmlquery.Node) *bitmaskType {
 	rval := bitmaskType{}
+	rval.comment = node.SelectAttr("comment")
 
 	if alias := node.SelectAttr("alias"); alias != "" {
 		rval.aliasTypeName = alias
@@ -0,0 +1,49 @@
+package def
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestBitmaskBitwidth(t *testing.T) {
+	tr, vr := readTestRegistry(t, flagsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkFlags", "VkFlags64", "VkAccessFlags", "VkAccessFlags2",
+		"VkAccessFlagBits", "VkAccessFlagBits2", "VkOrphanFlagBits2")
+
+	for _, want := range []string{
+		"type AccessFlags Flags\n",
+		"type AccessFlags2 Flags64\n",
+		"ACCESS_HIGH_BIT AccessFlagBits = 1 << 31",
+		"ACCESS_2_SHADER_SAMPLED_READ_BIT AccessFlagBits2 = AccessFlagBits2(uint64(1) << 40)",
+		// No flags type refers to it, so the bitwidth alone decides
+		"type OrphanFlagBits2 = Flags64\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("want %q in\n%s", want, src)
+		}
+	}
+
+	// The bit types are aliases, so each bit has the type of its flags
+	pkg := typeCheck(t, src, "", "fmt", "strings")
+	tests := []struct {
+		name, wantType, wantValue string
+	}{
+		{"ACCESS_HIGH_BIT", "AccessFlags", "2147483648"},
+		{"ACCESS_2_SHADER_SAMPLED_READ_BIT", "AccessFlags2", "1099511627776"},
+		{"ORPHAN_2_BIT", "Flags64", "8589934592"},
+	}
+	for _, tt := range tests {
+		c, ok := pkg.Scope().Lookup(tt.name).(*types.Const)
+		if !ok {
+			t.Errorf("%s is not a constant", tt.name)
+			continue
+		}
+		if got := types.TypeString(types.Unalias(c.Type()), types.RelativeTo(pkg)); got != tt.wantType {
+			t.Errorf("%s: type %s, want %s", tt.name, got, tt.wantType)
+		}
+		if got := c.Val().ExactString(); got != tt.wantValue {
+			t.Errorf("%s: value %s, want %s", tt.name, got, tt.wantValue)
+		}
+	}
+}
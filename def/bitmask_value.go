@@ -3,14 +3,21 @@ package def
 import (
 	"fmt"
 	"io"
+	"math/bits"
+	"strconv"
+	"strings"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/sirupsen/logrus"
 )
 
+// bitmaskValue is a flag bit, given by a bitpos attribute, or a named mask given by a value attribute. A mask may set
+// several bits, like VK_SHADER_STAGE_ALL_GRAPHICS, or none, like the _NONE values.
 type bitmaskValue struct {
 	genericValue
 
 	bitposString string
+	is64Bit      bool
 
 	comment string
 }
@@ -18,6 +25,9 @@ type bitmaskValue struct {
 func (v *bitmaskValue) ValueString() string {
 	if v.IsAlias() {
 		return v.resolvedAliasValue.PublicName()
+	} else if v.bitposString != "" && v.is64Bit {
+		// Bit positions can be 32 or higher in 64-bit flag types, so the shift must be done on a 64-bit constant
+		return fmt.Sprintf("uint64(1) << %s", v.bitposString)
 	} else if v.bitposString != "" {
 		return fmt.Sprintf("1 << %s", v.bitposString)
 	} else {
@@ -25,8 +35,33 @@ func (v *bitmaskValue) ValueString() string {
 	}
 }
 
+// singleBit returns the position of the one bit set by the value, whether it was given as a bitpos or a value. Aliases,
+// zero values and masks of several bits return false, so that String() only decomposes a flags value into single bits.
+func (v *bitmaskValue) singleBit() (int, bool) {
+	if v.IsAlias() {
+		return 0, false
+	}
+	if v.bitposString != "" {
+		pos, err := strconv.Atoi(v.bitposString)
+		return pos, err == nil
+	}
+	n, ok := parseValueString(v.valueString)
+	if !ok || n <= 0 || n&(n-1) != 0 {
+		return 0, false
+	}
+	return bits.TrailingZeros64(uint64(n)), true
+}
+
 func (v *bitmaskValue) PrintPublicDeclaration(w io.Writer) {
-	fmt.Fprintf(w, "%s %s = %s\n", v.PublicName(), v.resolvedType.PublicName(), v.ValueString())
+	if !v.printOptions.isTyped(v.resolvedType) {
+		fmt.Fprintf(w, "%s = %s", v.PublicName(), v.ValueString())
+	} else if v.is64Bit && !v.IsAlias() && v.bitposString != "" {
+		// uint64 constant must be explicitly converted to the flag type
+		fmt.Fprintf(w, "%s %s = %s(%s)", v.PublicName(), v.resolvedType.PublicName(), v.resolvedType.PublicName(), v.ValueString())
+	} else {
+		fmt.Fprintf(w, "%s %s = %s", v.PublicName(), v.resolvedType.PublicName(), v.ValueString())
+	}
+	printValueComment(w, v.registryName, v.comment)
 }
 
 func (v *bitmaskValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
@@ -38,6 +73,12 @@ func (v *bitmaskValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	if v.IsAlias() {
 		v.resolvedAliasValue = vr[v.aliasValueName]
+		if v.resolvedAliasValue == nil {
+			logrus.WithField("registry name", v.registryName).
+				WithField("alias name", v.aliasValueName).
+				Error("alias not found in registry while resolving value")
+			return NewIncludeSet()
+		}
 		rval = v.resolvedAliasValue.Resolve(tr, vr)
 		v.valueString = RenameIdentifier(v.ValueString())
 
@@ -61,13 +102,29 @@ func NewBitmaskValueFromXML(forBitmask TypeDefiner, elt *xmlquery.Node) *bitmask
 	alias := elt.SelectAttr("alias")
 	if alias == "" {
 		rval.registryName = elt.SelectAttr("name")
-		rval.valueString = elt.SelectAttr("value")
 		rval.bitposString = elt.SelectAttr("bitpos")
+		// Masks are plain integers, possibly with a C suffix like 0x7FFFFFFFU; the typed complement forms used by API
+		// constants would not convert to the flags type
+		rval.valueString = elt.SelectAttr("value")
+		if match := rxIntLiteral.FindStringSubmatch(strings.TrimSpace(rval.valueString)); match != nil {
+			rval.valueString = match[1]
+		}
+		if (rval.bitposString == "") == (rval.valueString == "") {
+			logrus.WithField("registry name", rval.registryName).
+				WithField("bitpos", rval.bitposString).
+				WithField("value", rval.valueString).
+				Warn("bitmask value should have exactly one of bitpos or value")
+		}
 	} else {
 		rval.registryName = elt.SelectAttr("name")
 		rval.aliasValueName = alias
 	}
 	rval.underlyingTypeName = forBitmask.RegistryName()
+	rval.comment = elt.SelectAttr("comment")
+
+	if et, ok := forBitmask.(*enumType); ok {
+		rval.is64Bit = et.bitWidth == 64
+	}
 
 	return &rval
 }
@@ -0,0 +1,67 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBitmaskCombinedValues(t *testing.T) {
+	tr, vr := readTestRegistry(t, flagsFixture)
+
+	tests := []struct {
+		name       string
+		wantBit    int
+		wantSingle bool
+	}{
+		{"VK_SHADER_STAGE_VERTEX_BIT", 0, true},
+		{"VK_SHADER_STAGE_FRAGMENT_BIT", 4, true},
+		// A single bit given as a value is still a single bit
+		{"VK_SHADER_STAGE_COMPUTE_BIT", 5, true},
+		{"VK_SHADER_STAGE_ALL_GRAPHICS", 0, false},
+		{"VK_SHADER_STAGE_ALL", 0, false},
+		{"VK_SHADER_STAGE_NONE", 0, false},
+	}
+	for _, tt := range tests {
+		bv, ok := vr[tt.name].(*bitmaskValue)
+		if !ok {
+			t.Errorf("%s: got %T, want *bitmaskValue", tt.name, vr[tt.name])
+			continue
+		}
+		bit, single := bv.singleBit()
+		if bit != tt.wantBit || single != tt.wantSingle {
+			t.Errorf("%s: got %d, %v, want %d, %v", tt.name, bit, single, tt.wantBit, tt.wantSingle)
+		}
+	}
+
+	src := resolveAndPrint(t, tr, vr, "VkFlags", "VkShaderStageFlags", "VkShaderStageFlagBits",
+		"VkFlags64", "VkAccessFlags2", "VkAccessFlagBits2")
+	for _, want := range []string{
+		"SHADER_STAGE_ALL_GRAPHICS ShaderStageFlagBits = 0x0000001F",
+		// The C suffix is dropped so the mask converts to the flags type
+		"SHADER_STAGE_ALL ShaderStageFlagBits = 0x7FFFFFFF",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("want %q in\n%s", want, src)
+		}
+	}
+
+	// Masks are not checked by String(), so a mask prints as the single bits it sets
+	out := runGenerated(t, src, `
+	fmt.Println(SHADER_STAGE_ALL_GRAPHICS)
+	fmt.Println(SHADER_STAGE_ALL_GRAPHICS | SHADER_STAGE_COMPUTE_BIT)
+	fmt.Println(SHADER_STAGE_ALL)
+	fmt.Println(SHADER_STAGE_NONE)
+	fmt.Println(ACCESS_2_SHADER_SAMPLED_READ_BIT | ACCESS_2_INDIRECT_COMMAND_READ_BIT)`, "fmt", "strings")
+
+	graphics := "VK_SHADER_STAGE_VERTEX_BIT|VK_SHADER_STAGE_TESSELLATION_CONTROL_BIT|" +
+		"VK_SHADER_STAGE_TESSELLATION_EVALUATION_BIT|VK_SHADER_STAGE_GEOMETRY_BIT|VK_SHADER_STAGE_FRAGMENT_BIT"
+	want := graphics + "\n" +
+		graphics + "|VK_SHADER_STAGE_COMPUTE_BIT\n" +
+		graphics + "|VK_SHADER_STAGE_COMPUTE_BIT|0x7fffffc0\n" +
+		"0\n" +
+		// Bits above 31 are decomposed in 64-bit flags
+		"VK_ACCESS_2_INDIRECT_COMMAND_READ_BIT|VK_ACCESS_2_SHADER_SAMPLED_READ_BIT\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -25,8 +25,63 @@ type commandType struct {
 	bindingParams     []*commandParam
 	returnParams      []*commandParam
 	bindingParamCount int
+
+	successCodes, errorCodes []string
+
+	printOptions PrintOptions
+}
+
+// SuccessCodes returns the registry names of the results that the command returns on success, e.g. VK_SUCCESS and
+// VK_INCOMPLETE. It is empty for commands that do not return a VkResult.
+func (t *commandType) SuccessCodes() []string { return t.successCodes }
+
+// ErrorCodes returns the registry names of the error results listed for the command
+func (t *commandType) ErrorCodes() []string { return t.errorCodes }
+
+// HasPartialSuccess is true if the command has a success code other than VK_SUCCESS. Those codes are returned as a
+// non-nil error, so callers need to check for them before treating the result as a failure.
+func (t *commandType) HasPartialSuccess() bool {
+	for _, c := range t.successCodes {
+		if c != "VK_SUCCESS" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSuccessCode is true if code, e.g. VK_INCOMPLETE, is one of the command's success codes
+func (t *commandType) hasSuccessCode(code string) bool {
+	for _, c := range t.successCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
 }
 
+// printResultCodes adds the success and error codes for the command to its doc comment
+func (t *commandType) printResultCodes(w io.Writer) {
+	goNames := func(codes []string) string {
+		names := make([]string, len(codes))
+		for i, c := range codes {
+			names[i] = strings.TrimPrefix(c, "VK_")
+		}
+		return strings.Join(names, ", ")
+	}
+	if len(t.successCodes) > 0 {
+		fmt.Fprintf(w, "//\n// Success codes: %s\n", goNames(t.successCodes))
+	}
+	if len(t.errorCodes) > 0 {
+		fmt.Fprintf(w, "//\n// Error codes: %s\n", goNames(t.errorCodes))
+	}
+	if t.HasPartialSuccess() {
+		fmt.Fprint(w, "//\n// A success code other than SUCCESS is still returned as a non-nil error.\n")
+	}
+}
+
+// SetPrintOptions selects the optional wrappers printed with the command. See PrintConfigurer.
+func (t *commandType) SetPrintOptions(opts PrintOptions) { t.printOptions = opts }
+
 // Exceptions to camelCase rules used for function return params
 func init() {
 	// rename return params to avoid typenames
@@ -89,11 +144,14 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 		fmt.Fprintf(w, "var %s = %s\n\n", t.PublicName(), t.staticCodeRef)
 		return
 	} else if t.IsAlias() {
+		t.printDeprecation(w, false)
 		fmt.Fprintf(w, "var %s = %s\n\n", t.PublicName(), t.resolvedAliasType.PublicName())
 		return
 	}
 
 	preamble, epilogue, outputTranslation := &strings.Builder{}, &strings.Builder{}, &strings.Builder{}
+	// retryIncomplete wraps both calls of a double-call command in a loop, to start over if the count grew in between
+	retryIncomplete := false
 
 	funcReturnParams := make([]*commandParam, 0)
 	var trampolineReturns *commandParam
@@ -104,6 +162,11 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 		retParam := &commandParam{}
 		retParam.resolvedType = t.resolvedReturnType
 		retParam.publicName = strcase.ToLowerCamel(t.resolvedReturnType.PublicName())
+		if t.resolvedReturnType.RegistryName() == "VkBool32" {
+			// Named ok rather than r, as for other scalar results, since these commands are predicates; see
+			// vkGetPhysicalDeviceWin32PresentationSupportKHR
+			retParam.publicName = "ok"
+		}
 		funcReturnParams = append(funcReturnParams, retParam)
 		trampolineReturns = retParam
 	}
@@ -134,12 +197,12 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 					if p.requiresTranslation {
 						fmt.Fprintf(preamble, "  // %s is an input slice that requires translation to an internal type\n", p.publicName)
 						fmt.Fprintf(preamble, "  var %s unsafe.Pointer\n", p.internalName)
-						fmt.Fprintf(preamble, "  if len(%s) > 0 {\n", p.publicName)
+						fmt.Fprintf(preamble, "  if %s {\n", p.sliceGuard())
 						fmt.Fprintf(preamble, "    sl_%s := make([]%s, %s)\n", p.publicName, paramTypeAsPointer.resolvedPointsAtType.InternalName(), p.lenMemberParam.publicName)
 						fmt.Fprintf(preamble, "    for i, v := range %s {\n", p.publicName)
 						fmt.Fprintf(preamble, "      sl_%s[i] = %s\n", p.publicName, paramTypeAsPointer.resolvedPointsAtType.TranslateToInternal("v"))
 						fmt.Fprintf(preamble, "    }\n")
-						fmt.Fprintf(preamble, "    %s = unsafe.Pointer(&sl_%s[0])\n", p.internalName, p.publicName)
+						fmt.Fprintf(preamble, "    %s = unsafe.Pointer(unsafe.SliceData(sl_%s))\n", p.internalName, p.publicName)
 						fmt.Fprintf(preamble, "  }\n")
 						fmt.Fprintln(preamble)
 
@@ -150,8 +213,8 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 						// to the first element)
 						fmt.Fprintf(preamble, "  // %s is an input slice of values that do not need translation used\n", p.publicName)
 						fmt.Fprintf(preamble, "  var %s unsafe.Pointer\n", p.internalName)
-						fmt.Fprintf(preamble, "  if %s != nil {\n", p.publicName)
-						fmt.Fprintf(preamble, "    %s = unsafe.Pointer(&%s[0])\n", p.internalName, p.publicName)
+						fmt.Fprintf(preamble, "  if %s {\n", p.sliceGuard())
+						fmt.Fprintf(preamble, "    %s = unsafe.Pointer(unsafe.SliceData(%s))\n", p.internalName, p.publicName)
 						fmt.Fprintf(preamble, "  }\n")
 						fmt.Fprintln(preamble)
 						funcTrampolineParams = append(funcTrampolineParams, p)
@@ -179,7 +242,7 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 					fmt.Fprintf(preamble, "  // %s is an edge case input slice, with an alternative length encoding. Developer must provide the length themselves.\n", p.publicName)
 					fmt.Fprintf(preamble, "  // No handling for internal vs. external types at this time, the only case this appears as of 1.3.240 is a handle type with a bitfield length encoding\n")
 					fmt.Fprintf(preamble, "  var %s *%s\n", p.internalName, p.resolvedType.(*pointerType).resolvedPointsAtType.PublicName())
-					fmt.Fprintf(preamble, "  if %s != nil {\n", p.publicName)
+					fmt.Fprintf(preamble, "  if len(%s) > 0 {\n", p.publicName)
 					fmt.Fprintf(preamble, "    %s = &%s[0]\n", p.internalName, p.publicName)
 					fmt.Fprintf(preamble, "  }\n")
 
@@ -226,9 +289,20 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 							fmt.Fprintf(preamble, "  var %s %s\n", p.lenMemberParam.publicName, p.lenMemberParam.resolvedType.(*pointerType).resolvedPointsAtType.PublicName())
 							fmt.Fprintf(preamble, "  %s := &%s\n", p.lenMemberParam.internalName, p.lenMemberParam.publicName)
 
-							fmt.Fprintf(preamble, "// first trampoline happens here; also, still need to check returned Result value\n")
+							fmt.Fprintf(preamble, "// first trampoline happens here\n")
 							funcTrampolineParams = append(funcTrampolineParams, p.lenMemberParam)
 
+							// The length is only known after the first call. Skip the second call if the first one
+							// failed or there is nothing to return, which also avoids indexing into an empty slice.
+							if trampolineReturns != nil && trampolineReturns.resolvedType.RegistryName() == "VkResult" {
+								retryIncomplete = t.hasSuccessCode("VK_INCOMPLETE")
+								fmt.Fprintf(epilogue, "  if %s != Result(0) {\n    return\n  }\n", trampolineReturns.publicName)
+								fmt.Fprintf(epilogue, "  if %s == 0 {\n    %s = SUCCESS\n    return\n  }\n", p.lenMemberParam.publicName, trampolineReturns.publicName)
+							} else {
+								fmt.Fprintf(epilogue, "  if %s == 0 {\n    return\n  }\n", p.lenMemberParam.publicName)
+							}
+							fmt.Fprintln(epilogue)
+
 						}
 
 						// Need distinction between identical interal/external types and those that need to be
@@ -264,9 +338,37 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 							t.printTrampolineCall(epilogue, funcTrampolineParams, trampolineReturns)
 							fmt.Fprintln(epilogue)
 
+							if retryIncomplete {
+								// Start over with nil arrays, so that the first call gets the new count
+								fmt.Fprintf(epilogue, "  if %s == INCOMPLETE {\n", trampolineReturns.publicName)
+								for _, a := range p.lenMemberParam.isLenMemberFor {
+									if !a.resolvedType.IsIdenticalPublicAndInternal() {
+										fmt.Fprintf(epilogue, "    %s = nil\n", a.internalName)
+									}
+								}
+								fmt.Fprint(epilogue, "    continue\n  }\n")
+							}
+							if trampolineReturns != nil && trampolineReturns.resolvedType.RegistryName() == "VkResult" {
+								// Nothing is returned with an error, as the arrays may not have been written
+								fmt.Fprintf(epilogue, "  if %s != Result(0) {\n", trampolineReturns.publicName)
+								for _, a := range p.lenMemberParam.isLenMemberFor {
+									fmt.Fprintf(epilogue, "    %s = nil\n", a.publicName)
+								}
+								fmt.Fprint(epilogue, "    return\n  }\n")
+							}
+							// Vulkan may also write fewer elements than it first reported
+							for _, a := range p.lenMemberParam.isLenMemberFor {
+								if !a.resolvedType.IsIdenticalPublicAndInternal() {
+									fmt.Fprintf(epilogue, "  sl_%s = sl_%s[:%s]\n", a.internalName, a.internalName, p.lenMemberParam.publicName)
+								}
+								fmt.Fprintf(epilogue, "  %s = %s[:%s]\n", a.publicName, a.publicName, p.lenMemberParam.publicName)
+							}
+
 							// If the output requires translation, iterate the slice and translate here
 							fmt.Fprintf(epilogue, outputTranslation.String())
-
+							if retryIncomplete {
+								fmt.Fprint(epilogue, "  break\n}\n")
+							}
 						}
 
 					} else if p.lenMemberParam != nil {
@@ -358,6 +460,12 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 									fmt.Fprintf(preamble, "var %s %s = %s\n", p.internalName, p.resolvedType.InternalName(), p.resolvedType.TranslateToInternal(p.publicName))
 
 									fmt.Fprintf(epilogue, "  %s = %s\n", p.publicName, p.resolvedType.(*pointerType).resolvedPointsAtType.TranslateToPublic(p.internalName))
+								} else if p.isBool32Output() {
+									// Predicates like vkGetPhysicalDeviceSurfaceSupportKHR; the zero value is passed in
+									// and Vulkan writes VK_TRUE or VK_FALSE, returned as a Go bool
+									fmt.Fprintf(preamble, "var internal_%s %s\n", p.publicName, underlyingType.InternalName())
+									fmt.Fprintf(preamble, "var %s = &internal_%s\n", p.internalName, p.publicName)
+									fmt.Fprintf(epilogue, "  %s = %s\n", p.publicName, underlyingType.TranslateToPublic("internal_"+p.publicName))
 								} else {
 									fmt.Fprintf(preamble, "var internal_%s %s = %s\n", p.publicName, underlyingType.InternalName(), underlyingType.TranslateToInternal(p.publicName))
 									fmt.Fprintf(preamble, "var %s = &internal_%s\n", p.internalName, p.publicName)
@@ -440,8 +548,17 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 
 	inputSpecString, _ := specStringFromParams(funcInputParams)
 	returnSpecString, hasResult := specStringFromParams(funcReturnParams)
+	if t.printOptions.MustWrappers && hasResult {
+		defer t.printMustWrapper(w, inputSpecString, funcInputParams, funcReturnParams)
+	}
+	if t.printOptions.HandleMethods && len(funcInputParams) > 0 && funcInputParams[0] == t.parameters[0] &&
+		t.parameters[0].resolvedType.Category() == CatHandle {
+		methodSpecString, _ := specStringFromParams(funcInputParams[1:])
+		defer t.printHandleMethod(w, methodSpecString, returnSpecString, funcInputParams, len(funcReturnParams) > 0)
+	}
 
 	t.PrintDocLink(w)
+	t.printResultCodes(w)
 	fmt.Fprintf(w, "func %s(%s) (%s) {\n",
 		t.PublicName(),
 		inputSpecString,
@@ -449,6 +566,9 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 
 	fmt.Fprintln(w, preamble.String())
 
+	if retryIncomplete {
+		fmt.Fprint(w, "for {\n")
+	}
 	t.printTrampolineCall(w, funcTrampolineParams, trampolineReturns)
 	fmt.Fprintln(w)
 
@@ -466,6 +586,107 @@ func (t *commandType) PrintPublicDeclaration(w io.Writer) {
 
 	fmt.Fprintf(w, "var %s = &vkCommand{\"%s\", %d, %v, nil}\n",
 		t.RegistryName(), t.RegistryName(), t.bindingParamCount, t.resolvedReturnType != nil)
+
+	t.printDestructorMethod(w)
+}
+
+// printDestructorMethod writes a Destroy or Free method on the handle type destroyed by this command, if it is a
+// vkDestroy<Handle> or vkFree<Handle> command, e.g. device.Destroy(nil) for DestroyDevice(device, nil). Any other
+// parameters, like the parent device, are kept as method parameters in their original order.
+//
+// Free commands are not named after the full handle type (vkFreeMemory frees a VkDeviceMemory), so the receiver is the
+// first handle parameter after the parent whose type ends with the suffix. Commands freeing arrays of handles, like
+// vkFreeCommandBuffers, take a slice and get no method.
+func (t *commandType) printDestructorMethod(w io.Writer) {
+	var verb, suffix string
+	for _, v := range []string{"Destroy", "Free"} {
+		if s := strings.TrimPrefix(t.registryName, "vk"+v); s != t.registryName {
+			verb, suffix = v, s
+		}
+	}
+	if verb == "" || t.resolvedReturnType.RegistryName() != "void" {
+		return
+	}
+
+	isReceiver := func(i int, p *commandParam) bool {
+		if p.resolvedType.Category() != CatHandle {
+			return false
+		}
+		if verb == "Destroy" {
+			return p.resolvedType.RegistryName() == "Vk"+suffix
+		}
+		return i > 0 && strings.HasSuffix(p.resolvedType.RegistryName(), suffix)
+	}
+
+	var receiver *commandParam
+	var methodParams, callArgs []string
+	for i, p := range t.parameters {
+		if receiver == nil && isReceiver(i, p) {
+			receiver = p
+			callArgs = append(callArgs, "h")
+			continue
+		}
+		if p.isPublicSlice || p.resolvedType.Category() == CatPointer && !p.isConstParam {
+			// Not a simple input, the method would not be a thin wrapper
+			return
+		}
+		methodParams = append(methodParams, fmt.Sprintf("%s %s", p.publicName, p.resolvedType.PublicName()))
+		callArgs = append(callArgs, p.publicName)
+	}
+	if receiver == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "// %s calls %s with h as the %s parameter\n", verb, t.PublicName(), receiver.publicName)
+	fmt.Fprintf(w, "func (h %s) %s(%s) {\n", receiver.resolvedType.PublicName(), verb, strings.Join(methodParams, ", "))
+	fmt.Fprintf(w, "%s(%s)\n}\n\n", t.PublicName(), strings.Join(callArgs, ", "))
+}
+
+// printHandleMethod writes a method on the handle type of the first parameter, which calls the command with the
+// receiver as that parameter. The method has the command's name, so it can't collide with the Destroy, Free and
+// ObjectType methods that handles already have.
+func (t *commandType) printHandleMethod(w io.Writer, inputSpecString, returnSpecString string, inputParams []*commandParam, hasReturns bool) {
+	callArgs := []string{"h"}
+	for _, p := range inputParams[1:] {
+		callArgs = append(callArgs, p.publicName)
+	}
+
+	fmt.Fprintf(w, "// %s calls %s with h as the %s parameter\n", t.PublicName(), t.PublicName(), inputParams[0].publicName)
+	fmt.Fprintf(w, "func (h %s) %s(%s) (%s) {\n", inputParams[0].resolvedType.PublicName(), t.PublicName(), inputSpecString, returnSpecString)
+	if hasReturns {
+		fmt.Fprint(w, "  return ")
+	}
+	fmt.Fprintf(w, "%s(%s)\n}\n\n", t.PublicName(), strings.Join(callArgs, ", "))
+}
+
+// printMustWrapper writes Must<Command>, which calls the command and panics with the error string if it does not
+// return SUCCESS. Commands with other success codes, like VK_INCOMPLETE or VK_SUBOPTIMAL_KHR, are skipped, because
+// those results are returned as a non-nil error and are not failures.
+func (t *commandType) printMustWrapper(w io.Writer, inputSpecString string, inputParams, returnParams []*commandParam) {
+	if len(t.successCodes) == 0 || t.HasPartialSuccess() {
+		return
+	}
+
+	var callArgs, returnNames, returnTypes []string
+	for _, p := range inputParams {
+		callArgs = append(callArgs, p.publicName)
+	}
+	for _, p := range returnParams {
+		if p.resolvedType.RegistryName() == "VkResult" {
+			continue
+		}
+		returnNames = append(returnNames, p.publicName)
+		returnTypes = append(returnTypes, p.resolvedType.PublicName())
+	}
+
+	fmt.Fprintf(w, "// Must%s calls %s and panics if it returns an error\n", t.PublicName(), t.PublicName())
+	fmt.Fprintf(w, "func Must%s(%s) (%s) {\n", t.PublicName(), inputSpecString, strings.Join(returnTypes, ", "))
+	fmt.Fprintf(w, "  %s := %s(%s)\n", strings.Join(append(returnNames, "r"), ", "), t.PublicName(), strings.Join(callArgs, ", "))
+	fmt.Fprint(w, "  if r != SUCCESS {\npanic(r.Error())\n}\n")
+	if len(returnNames) > 0 {
+		fmt.Fprintf(w, "  return %s\n", strings.Join(returnNames, ", "))
+	}
+	fmt.Fprintf(w, "}\n\n")
 }
 
 // cgoTrampolineArgsFromParams generates the argument string for direct C.Trampoline calls.
@@ -560,6 +781,88 @@ func (t *commandType) printTrampolineCall(w io.Writer, trampParams []*commandPar
 	}
 }
 
+// CommandLevel classifies a command by the dispatchable handle it is called through, which determines how its function
+// pointer must be loaded.
+type CommandLevel int
+
+const (
+	// Global commands, like vkCreateInstance, are not called through a dispatchable handle and are loaded with a NULL
+	// instance
+	CommandLevelGlobal CommandLevel = iota
+	CommandLevelInstance
+	CommandLevelDevice
+)
+
+// Level returns the dispatch level of the command, based on the type of its first parameter. Aliases use the level
+// of the aliased command.
+func (t *commandType) Level() CommandLevel {
+	if t.IsAlias() {
+		if ct, ok := t.resolvedAliasType.(*commandType); ok {
+			return ct.Level()
+		}
+	}
+	if len(t.parameters) == 0 {
+		return CommandLevelGlobal
+	}
+
+	switch t.parameters[0].typeName {
+	case "VkDevice", "VkQueue", "VkCommandBuffer":
+		return CommandLevelDevice
+	case "VkInstance", "VkPhysicalDevice":
+		return CommandLevelInstance
+	default:
+		return CommandLevelGlobal
+	}
+}
+
+// WriteCommandTable writes a CommandTable struct with a function pointer field for each command in cmds, and methods
+// to load those pointers through vkGetInstanceProcAddr or vkGetDeviceProcAddr. Commands implemented as static code are
+// skipped.
+func WriteCommandTable(w io.Writer, cmds []TypeDefiner) {
+	byLevel := make(map[CommandLevel][]*commandType)
+	for _, td := range cmds {
+		ct, ok := td.(*commandType)
+		if !ok || ct.staticCodeRef != "" {
+			continue
+		}
+		byLevel[ct.Level()] = append(byLevel[ct.Level()], ct)
+	}
+
+	fmt.Fprint(w, "// CommandTable holds a function pointer for each Vulkan command. Fields are nil until loaded, and will remain\n")
+	fmt.Fprint(w, "// nil if the command is not provided by the implementation or by an enabled extension.\n")
+	fmt.Fprint(w, "type CommandTable struct {\n")
+	fmt.Fprint(w, "// Global commands\n")
+	for _, ct := range byLevel[CommandLevelGlobal] {
+		fmt.Fprintf(w, "%s unsafe.Pointer\n", ct.PublicName())
+	}
+	fmt.Fprint(w, "\n// Instance-level commands\n")
+	for _, ct := range byLevel[CommandLevelInstance] {
+		fmt.Fprintf(w, "%s unsafe.Pointer\n", ct.PublicName())
+	}
+	fmt.Fprint(w, "\n// Device-level commands\n")
+	for _, ct := range byLevel[CommandLevelDevice] {
+		fmt.Fprintf(w, "%s unsafe.Pointer\n", ct.PublicName())
+	}
+	fmt.Fprint(w, "}\n\n")
+
+	fmt.Fprint(w, "// Load populates every command through getProcAddr, which will typically wrap vkGetInstanceProcAddr.\n")
+	fmt.Fprint(w, "func (t *CommandTable) Load(getProcAddr func(name string) unsafe.Pointer) {\n")
+	fmt.Fprint(w, "t.LoadGlobal(getProcAddr)\nt.LoadInstance(getProcAddr)\nt.LoadDevice(getProcAddr)\n}\n\n")
+
+	writeLoadMethod(w, "LoadGlobal", "Global commands must be loaded with vkGetInstanceProcAddr and a NULL instance.", byLevel[CommandLevelGlobal])
+	writeLoadMethod(w, "LoadInstance", "Instance-level commands must be loaded with vkGetInstanceProcAddr.", byLevel[CommandLevelInstance])
+	writeLoadMethod(w, "LoadDevice", "Device-level commands loaded with vkGetDeviceProcAddr bypass the loader's dispatch.", byLevel[CommandLevelDevice])
+}
+
+func writeLoadMethod(w io.Writer, methodName, comment string, cmds []*commandType) {
+	fmt.Fprintf(w, "// %s populates only the commands at that level. %s\n", methodName, comment)
+	fmt.Fprintf(w, "func (t *CommandTable) %s(getProcAddr func(name string) unsafe.Pointer) {\n", methodName)
+	for _, ct := range cmds {
+		fmt.Fprintf(w, "t.%s = getProcAddr(\"%s\")\n", ct.PublicName(), ct.RegistryName())
+	}
+	fmt.Fprint(w, "}\n\n")
+}
+
 // There is no internal declaration for commands, this function is empty
 func (t *commandType) PrintInternalDeclaration(w io.Writer) {}
 
@@ -589,6 +892,25 @@ type commandParam struct {
 	requiresTranslation                        bool
 }
 
+// isBool32Output reports whether the parameter is a single VkBool32 written by Vulkan, like the pSupported parameter of
+// vkGetPhysicalDeviceSurfaceSupportKHR. It is returned from the command as a bool.
+func (p *commandParam) isBool32Output() bool {
+	pt, ok := p.resolvedType.(*pointerType)
+	return ok && !p.isConstParam && p.lenSpec == "" && p.pointerLevel == 1 &&
+		pt.resolvedPointsAtType.RegistryName() == "VkBool32"
+}
+
+// sliceGuard returns the condition for passing a pointer to the elements of an input slice. Vulkan expects a nil
+// pointer with a zero count, so an empty slice is passed as nil when the parameter is optional. For parameters without
+// optional, a non-nil empty slice still gives a non-nil pointer, since some commands treat that case differently from
+// nil. unsafe.SliceData is used for both, so an empty slice never panics.
+func (p *commandParam) sliceGuard() string {
+	if strings.HasPrefix(p.optionalParamString, "true") {
+		return fmt.Sprintf("len(%s) > 0", p.publicName)
+	}
+	return fmt.Sprintf("%s != nil", p.publicName)
+}
+
 func (p *commandParam) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	if p.isResolved {
 		return NewIncludeSet()
@@ -724,6 +1046,12 @@ func NewCommandFromXML(elt *xmlquery.Node, api string) *commandType {
 	} else {
 		rval.registryName = xmlquery.FindOne(elt, "/proto/name").InnerText()
 		rval.returnTypeName = xmlquery.FindOne(elt, "/proto/type").InnerText()
+		if codes := elt.SelectAttr("successcodes"); codes != "" {
+			rval.successCodes = strings.Split(codes, ",")
+		}
+		if codes := elt.SelectAttr("errorcodes"); codes != "" {
+			rval.errorCodes = strings.Split(codes, ",")
+		}
 
 		paramQueryString := fmt.Sprintf("param[(contains(@api,'%s') and not(@api='vulkansc')) or not(@api)]", api)
 		for _, m := range xmlquery.Find(elt, paramQueryString) {
@@ -747,6 +1075,14 @@ func NewCommandParamFromXML(elt *xmlquery.Node, forCommand *commandType) *comman
 	rval.pointerLevel = strings.Count(elt.InnerText(), "*")
 	rval.lenSpec = elt.SelectAttr("len")
 	rval.altLenSpec = elt.SelectAttr("altlen")
+	if strings.HasPrefix(rval.lenSpec, "latexmath:") && rval.altLenSpec == "" {
+		// A formula can't be matched to another parameter, see splitLenSpec. Pass the pointer through as is.
+		log.WithField("command", forCommand.registryName).
+			WithField("param", rval.registryName).
+			WithField("len", rval.lenSpec).
+			Warn("parameter length is a formula without an altlen, treating the parameter as a plain pointer")
+		rval.lenSpec = ""
+	}
 
 	rval.parentCommand = forCommand
 
@@ -0,0 +1,252 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmptySliceParams(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		// Not optional, so a non-nil empty slice is still passed as a pointer
+		{"vkCmdSetViewport", "if viewports != nil {\n    pViewports = unsafe.Pointer(unsafe.SliceData(viewports))"},
+		// Optional, so an empty slice is passed as nil with a zero count
+		{"vkCmdSetScissor", "if len(scissors) > 0 {\n    pScissors = unsafe.Pointer(unsafe.SliceData(scissors))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, commandsFixture)
+			src := resolveAndPrint(t, tr, vr, tt.command)
+			if !strings.Contains(src, tt.want) {
+				t.Errorf("want %q in\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+func TestResultCodes(t *testing.T) {
+	tests := []struct {
+		command          string
+		success, errors  string
+		partial, hasMust bool
+		want             []string
+	}{
+		{"vkAcquireNextImageKHR", "VK_SUCCESS,VK_TIMEOUT,VK_NOT_READY,VK_SUBOPTIMAL_KHR", "VK_ERROR_OUT_OF_HOST_MEMORY,VK_ERROR_DEVICE_LOST", true, false, []string{
+			"// Success codes: SUCCESS, TIMEOUT, NOT_READY, SUBOPTIMAL_KHR\n",
+			"// Error codes: ERROR_OUT_OF_HOST_MEMORY, ERROR_DEVICE_LOST\n",
+			"// A success code other than SUCCESS is still returned as a non-nil error.\n",
+		}},
+		{"vkQueueWaitIdle", "VK_SUCCESS", "VK_ERROR_OUT_OF_HOST_MEMORY,VK_ERROR_DEVICE_LOST", false, true, []string{
+			"// Success codes: SUCCESS\n",
+			"func MustQueueWaitIdle(queue Queue) (",
+		}},
+		{"vkDestroyDevice", "", "", false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, commandsFixture)
+			SetPrintOptions(tr, vr, PrintOptions{MustWrappers: true})
+			src := resolveAndPrint(t, tr, vr, tt.command)
+
+			ct := tr[tt.command].(*commandType)
+			if got := strings.Join(ct.SuccessCodes(), ","); got != tt.success {
+				t.Errorf("success codes %s, want %s", got, tt.success)
+			}
+			if got := strings.Join(ct.ErrorCodes(), ","); got != tt.errors {
+				t.Errorf("error codes %s, want %s", got, tt.errors)
+			}
+			if ct.HasPartialSuccess() != tt.partial {
+				t.Errorf("HasPartialSuccess is %v, want %v", ct.HasPartialSuccess(), tt.partial)
+			}
+			if got := strings.Contains(src, "func Must"); got != tt.hasMust {
+				t.Errorf("Must wrapper generated is %v, want %v in\n%s", got, tt.hasMust, src)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(src, want) {
+					t.Errorf("want %q in\n%s", want, src)
+				}
+			}
+			if tt.success == "" && strings.Contains(src, "codes:") {
+				t.Errorf("unexpected result codes in\n%s", src)
+			}
+		})
+	}
+}
+
+func TestBool32Predicates(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"vkGetPhysicalDeviceWin32PresentationSupportKHR", []string{
+			"func GetPhysicalDeviceWin32PresentationSupportKHR(physicalDevice PhysicalDevice, queueFamilyIndex uint32) (ok bool) {",
+			"ok = translatePublic_Bool32(rval)",
+		}},
+		{"vkGetPhysicalDeviceSurfaceSupportKHR", []string{
+			"func GetPhysicalDeviceSurfaceSupportKHR(physicalDevice PhysicalDevice, queueFamilyIndex uint32, surface SurfaceKHR) (supported bool, r error) {",
+			"var internal_supported Bool32\n",
+			"var pSupported = &internal_supported",
+			"supported = translatePublic_Bool32(internal_supported)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, commandsFixture)
+			src := resolveAndPrint(t, tr, vr, tt.command)
+			for _, want := range tt.want {
+				if !strings.Contains(src, want) {
+					t.Errorf("want %q in\n%s", want, src)
+				}
+			}
+		})
+	}
+}
+
+func TestDestructorMethods(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string // empty if no method should be generated
+	}{
+		{"vkDestroyBuffer", "func (h Buffer) Destroy(device Device, allocator *AllocationCallbacks) {\nDestroyBuffer(device, h, allocator)\n}"},
+		{"vkFreeMemory", "func (h DeviceMemory) Free(device Device, allocator *AllocationCallbacks) {\nFreeMemory(device, h, allocator)\n}"},
+		{"vkFreeCommandBuffers", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, commandsFixture)
+			src := resolveAndPrint(t, tr, vr, tt.command)
+			if tt.want == "" {
+				if strings.Contains(src, "func (h ") {
+					t.Errorf("want no method in\n%s", src)
+				}
+			} else if !strings.Contains(src, tt.want) {
+				t.Errorf("want\n%s\nin\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+// trampolineStubs stands in for the cgo trampolines and the static declarations that generated commands call. Every
+// command pointer runs fakeTrampoline, which the test sets.
+const trampolineStubs = `
+type c_uintptr_t = uintptr
+
+type vkCommand struct {
+	protoName string
+	argCount  int
+	hasReturn bool
+	fnHandle  unsafe.Pointer
+}
+
+var dlHandle unsafe.Pointer
+
+func initDlHandle() {}
+
+func sys_stringToBytePointer(s string) *byte { return nil }
+
+func c_SymbolFromName(lib, name unsafe.Pointer) unsafe.Pointer { return unsafe.Pointer(&fakeTrampoline) }
+
+var fakeTrampoline func(a, b, c c_uintptr_t) c_uintptr_t
+
+func c_Trampoline3(fn unsafe.Pointer, a, b, c c_uintptr_t) c_uintptr_t { return fakeTrampoline(a, b, c) }
+`
+
+// enumerateStub simulates an enumeration command with fakeEnumerate: fakeAvailable[i] is the number of elements that
+// exist at the i-th call, and the call numbered fakeFailAt returns an error.
+const enumerateStub = `
+var (
+	fakeAvailable        []uint32
+	fakeCall, fakeFailAt int
+)
+
+func fakeEnumerate(handle, pCount, pData c_uintptr_t) c_uintptr_t {
+	defer func() { fakeCall++ }()
+	if fakeCall == fakeFailAt {
+		r := ERROR_OUT_OF_HOST_MEMORY
+		return c_uintptr_t(r)
+	}
+	n := fakeAvailable[len(fakeAvailable)-1]
+	if fakeCall < len(fakeAvailable) {
+		n = fakeAvailable[fakeCall]
+	}
+	count := (*uint32)(unsafe.Pointer(pCount))
+	if pData == 0 {
+		*count = n
+		return 0
+	}
+	out := unsafe.Slice((*PhysicalDevice)(unsafe.Pointer(pData)), *count)
+	written := *count
+	if n < written {
+		written = n
+	}
+	for i := range out[:written] {
+		out[i] = PhysicalDevice(i + 1)
+	}
+	*count = written
+	if n > written {
+		return c_uintptr_t(INCOMPLETE)
+	}
+	return 0
+}
+`
+
+func TestEnumerateCommand(t *testing.T) {
+	tr, vr := readTestRegistry(t, commandsFixture)
+	src := resolveAndPrint(t, tr, vr, "VK_DEFINE_HANDLE", "VkInstance", "VkPhysicalDevice", "VkResult",
+		"vkEnumeratePhysicalDevices")
+	src = strings.ReplaceAll(src, "C.", "c_") + trampolineStubs + enumerateStub
+
+	out := runGenerated(t, src, `	fakeTrampoline = fakeEnumerate
+	for _, c := range []struct {
+		available []uint32
+		failAt    int
+	}{
+		{[]uint32{3}, -1},
+		{[]uint32{0}, -1},
+		// Grows between the count and data calls, twice
+		{[]uint32{2, 3, 3, 4, 4}, -1},
+		// Shrinks, only the written elements are returned
+		{[]uint32{3, 2}, -1},
+		{[]uint32{3}, 0},
+		{[]uint32{3}, 1},
+	} {
+		fakeAvailable, fakeCall, fakeFailAt = c.available, 0, c.failAt
+		devices, err := EnumeratePhysicalDevices(Instance(1))
+		fmt.Println(devices, err, fakeCall)
+	}`, "fmt", "runtime", "unsafe")
+
+	want := "[1 2 3] <nil> 2\n" +
+		"[] <nil> 1\n" +
+		"[1 2 3 4] <nil> 6\n" +
+		"[1 2] <nil> 2\n" +
+		"[] VK_ERROR_OUT_OF_HOST_MEMORY 1\n" +
+		"[] VK_ERROR_OUT_OF_HOST_MEMORY 2\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestBool32Result(t *testing.T) {
+	tr, vr := readTestRegistry(t, commandsFixture)
+	src := resolveAndPrint(t, tr, vr, "VK_DEFINE_HANDLE", "VkPhysicalDevice",
+		"vkGetPhysicalDeviceWin32PresentationSupportKHR")
+	src = strings.ReplaceAll(src, "C.", "c_") + trampolineStubs + readStatic(t, "static_basetype.go") + basetypeStubs
+
+	out := runGenerated(t, src, `	for _, r := range []c_uintptr_t{0, 1, 2, 0xFFFFFFFF} {
+		fakeTrampoline = func(a, b, c c_uintptr_t) c_uintptr_t { return r }
+		fmt.Println(r, GetPhysicalDeviceWin32PresentationSupportKHR(PhysicalDevice(1), 0))
+	}`, "fmt", "unsafe")
+
+	// Only 0 is false, as in C
+	want := "0 false\n" +
+		"1 true\n" +
+		"2 true\n" +
+		"4294967295 true\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
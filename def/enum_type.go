@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/antchfx/xmlquery"
 	"github.com/sirupsen/logrus"
@@ -15,6 +17,7 @@ type enumType struct {
 	requiresTypeName     string
 	resolvedRequiresType TypeDefiner
 	isBitmaskType        bool
+	bitWidth             int
 }
 
 func (t *enumType) Category() TypeCategory { return CatEnum }
@@ -47,7 +50,7 @@ func (t *enumType) PrintPublicDeclaration(w io.Writer) {
 	sort.Sort(ByValue(t.values))
 
 	if t.RegistryName() == "VkResult" {
-		fmt.Fprintf(w, "// Command completed successfully\nvar SUCCESS error = nil\n")
+		t.printResultMethods(w)
 	}
 
 	if len(t.values) > 0 {
@@ -56,7 +59,127 @@ func (t *enumType) PrintPublicDeclaration(w io.Writer) {
 			v.PrintPublicDeclaration(w)
 		}
 		fmt.Fprint(w, ")\n\n")
+
+		if t.IsAlias() {
+			return
+		}
+		if t.isBitmaskType {
+			t.printBitmaskStringMethod(w)
+		} else {
+			t.printStringMethod(w)
+			t.printAllValues(w)
+			t.printParseFunction(w)
+		}
+	}
+}
+
+func (t *enumType) RegisterImports(reg map[string]bool) {
+	if len(t.values) > 0 && !t.IsAlias() {
+		reg["fmt"] = true
+		if t.isBitmaskType {
+			reg["strings"] = true
+		}
+	}
+}
+
+// printResultMethods writes the additional declarations that let VkResult be used as a Go error. Success codes are
+// non-negative (VK_SUCCESS, VK_SUBOPTIMAL_KHR, etc.) and error codes are negative.
+func (t *enumType) printResultMethods(w io.Writer) {
+	fmt.Fprintf(w, "// Command completed successfully\nvar SUCCESS error = nil\n\n")
+
+	fmt.Fprintf(w, "// Error implements the error interface\n")
+	fmt.Fprintf(w, "func (r %s) Error() string {\nreturn r.String()\n}\n\n", t.PublicName())
+
+	fmt.Fprintf(w, "// IsSuccess returns true for success codes, which are zero or positive. Error codes are negative.\n")
+	fmt.Fprintf(w, "func (r %s) IsSuccess() bool {\nreturn r >= 0\n}\n\n", t.PublicName())
+}
+
+// printStringMethod writes a String() method for the enum, returning the registry name of each value. Aliases and
+// any values duplicating an earlier value are skipped, so that each case in the switch is unique. Values must be
+// sorted before calling.
+func (t *enumType) printStringMethod(w io.Writer) {
+	fmt.Fprintf(w, "func (v %s) String() string {\n", t.PublicName())
+	fmt.Fprint(w, "switch v {\n")
+
+	seen := make(map[string]bool)
+	for _, v := range t.values {
+		if v.IsAlias() {
+			continue
+		}
+		valStr := v.ValueString()
+		key := valueKey(valStr)
+		if valStr == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		fmt.Fprintf(w, "case %s:\nreturn \"%s\"\n", valStr, v.RegistryName())
 	}
+
+	fmt.Fprintf(w, "default:\nreturn fmt.Sprintf(\"%s(%%d)\", v)\n", t.PublicName())
+	fmt.Fprint(w, "}\n}\n\n")
+}
+
+// valueKey returns the decimal form of a value string, so a hex value like 0x7FFFFFFF can't repeat a decimal value
+func valueKey(valStr string) string {
+	if n, ok := parseValueString(valStr); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	return valStr
+}
+
+// printAllValues writes an All<Enum> slice listing each distinct value of the enum in numeric order, for code that
+// needs to range over the valid values. As in String(), aliases and values duplicating an earlier value are skipped,
+// along with any _MAX_ENUM sentinel. Values must be sorted before calling.
+func (t *enumType) printAllValues(w io.Writer) {
+	fmt.Fprintf(w, "// All%s lists every %s value, in numeric order, without aliases\n", t.PublicName(), t.PublicName())
+	fmt.Fprintf(w, "var All%s = []%s{\n", t.PublicName(), t.PublicName())
+
+	seen := make(map[string]bool)
+	for _, v := range t.values {
+		if v.IsAlias() || strings.HasSuffix(v.RegistryName(), "_MAX_ENUM") {
+			continue
+		}
+		valStr := v.ValueString()
+		key := valueKey(valStr)
+		if valStr == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		fmt.Fprintf(w, "%s,\n", t.valueExpr(v))
+	}
+
+	fmt.Fprint(w, "}\n\n")
+}
+
+// valueExpr returns the Go expression for one of the enum's values. VK_SUCCESS is declared as a nil error instead of
+// a Result constant (see enumValue.PrintPublicDeclaration), so it is written as a typed literal.
+func (t *enumType) valueExpr(v ValueDefiner) string {
+	if !v.IsAlias() && t.registryName == "VkResult" && v.PublicName() == "SUCCESS" {
+		return fmt.Sprintf("%s(%s)", t.PublicName(), v.ValueString())
+	}
+	return v.PublicName()
+}
+
+// printParseFunction writes Parse<Enum>, the reverse of String(), mapping the registry name of a value to the value.
+// Alias names are accepted too, and give the value they alias.
+func (t *enumType) printParseFunction(w io.Writer) {
+	fmt.Fprintf(w, "// Parse%s returns the %s value with the given Vulkan name, e.g. the result of String(). Alias names are\n", t.PublicName(), t.PublicName())
+	fmt.Fprint(w, "// accepted as well. ok is false if no value has the name.\n")
+	fmt.Fprintf(w, "func Parse%s(s string) (v %s, ok bool) {\n", t.PublicName(), t.PublicName())
+	fmt.Fprint(w, "switch s {\n")
+
+	seen := make(map[string]bool)
+	for _, v := range t.values {
+		if v.ValueString() == "" || strings.HasSuffix(v.RegistryName(), "_MAX_ENUM") || seen[v.RegistryName()] {
+			continue
+		}
+		seen[v.RegistryName()] = true
+		fmt.Fprintf(w, "case \"%s\":\nreturn %s, true\n", v.RegistryName(), t.valueExpr(v))
+	}
+
+	fmt.Fprint(w, "}\nreturn 0, false\n}\n\n")
 }
 
 func ReadEnumTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, vr ValueRegistry, api string) {
@@ -81,8 +204,38 @@ func NewEnumTypeFromXML(node *xmlquery.Node) TypeDefiner {
 		rval.registryName = node.SelectAttr("name")
 	} else {
 		rval.registryName = node.SelectAttr("name")
+		// Enums are signed, as in C; VkResult error codes are negative. Flag bits types are switched to their flags
+		// type when their values are read, see ReadEnumValuesFromXML.
 		rval.underlyingTypeName = "int32_t"
 	}
+	rval.comment = node.SelectAttr("comment")
 
 	return &rval
 }
+
+// printBitmaskStringMethod writes a String() method for a flag bits type, joining the registry names of each set bit
+// with '|'. Only single-bit values are checked, see singleBit; aliases and multi-bit masks are skipped so the output is
+// canonical. Any remaining unknown bits are appended in hex.
+func (t *enumType) printBitmaskStringMethod(w io.Writer) {
+	fmt.Fprintf(w, "func (v %s) String() string {\n", t.PublicName())
+	fmt.Fprint(w, "if v == 0 {\nreturn \"0\"\n}\n")
+	fmt.Fprint(w, "var names []string\nbits := v\n")
+
+	seen := make(map[int]bool)
+	for _, v := range t.values {
+		bv, ok := v.(*bitmaskValue)
+		if !ok {
+			continue
+		}
+		bit, single := bv.singleBit()
+		if !single || seen[bit] {
+			continue
+		}
+		seen[bit] = true
+
+		fmt.Fprintf(w, "if v&%s != 0 {\nnames = append(names, \"%s\")\nbits &^= %s\n}\n", bv.PublicName(), bv.RegistryName(), bv.PublicName())
+	}
+
+	fmt.Fprintf(w, "if bits != 0 {\nnames = append(names, fmt.Sprintf(\"0x%%x\", uint64(bits)))\n}\n")
+	fmt.Fprint(w, "return strings.Join(names, \"|\")\n}\n\n")
+}
@@ -0,0 +1,119 @@
+package def
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestEnumParseRoundTrip(t *testing.T) {
+	tr, vr := readTestRegistry(t, enumsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkResult", "VkFilter")
+
+	out := runGenerated(t, src, `
+	for _, v := range AllResult {
+		if p, ok := ParseResult(v.String()); !ok || p != v {
+			fmt.Println("ParseResult", v.String(), p, ok)
+		}
+	}
+	for _, v := range AllFilter {
+		if p, ok := ParseFilter(v.String()); !ok || p != v {
+			fmt.Println("ParseFilter", v.String(), p, ok)
+		}
+	}
+	if p, ok := ParseResult("VK_ERROR_OUT_OF_POOL_MEMORY_KHR"); !ok || p != ERROR_OUT_OF_POOL_MEMORY {
+		fmt.Println("alias", p, ok)
+	}
+	if _, ok := ParseFilter("VK_FILTER_BOGUS"); ok {
+		fmt.Println("unknown name parsed")
+	}`, "fmt")
+
+	if out != "" {
+		t.Errorf("round trip failed:\n%s", out)
+	}
+}
+
+func TestSignedEnum(t *testing.T) {
+	tr, vr := readTestRegistry(t, enumsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkResult", "VkFilter")
+
+	if !strings.Contains(src, "type Result int32\n") {
+		t.Errorf("want a signed Result type in\n%s", src)
+	}
+
+	pkg := typeCheck(t, src, "", "fmt")
+	for _, name := range []string{"Result", "Filter"} {
+		if u := pkg.Scope().Lookup(name).Type().Underlying(); u != types.Typ[types.Int32] {
+			t.Errorf("%s: underlying type %s, want int32", name, u)
+		}
+	}
+	for name, want := range map[string]string{
+		"ERROR_OUT_OF_HOST_MEMORY": "-1",
+		"ERROR_OUT_OF_POOL_MEMORY": "-1000069000",
+		"FILTER_LINEAR":            "1",
+	} {
+		c, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if !ok {
+			t.Errorf("%s is not a constant", name)
+		} else if got := c.Val().ExactString(); got != want {
+			t.Errorf("%s: value %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestHexEnumValues(t *testing.T) {
+	tr, vr := readTestRegistry(t, enumsFixture)
+	if got := vr["VK_FORMAT_MAX_ENUM"].ValueString(); got != "0x7FFFFFFF" {
+		t.Errorf("VK_FORMAT_MAX_ENUM: got %s, want 0x7FFFFFFF", got)
+	}
+
+	src := resolveAndPrint(t, tr, vr, "VkFormat")
+
+	// Declared in numeric order, with hex and decimal values compared by value
+	want := "FORMAT_UNDEFINED Format = 0 // VK_FORMAT_UNDEFINED\n" +
+		"FORMAT_R8_UNORM Format = 9 // VK_FORMAT_R8_UNORM\n" +
+		"FORMAT_HEX_SIXTEEN Format = 0x10 // VK_FORMAT_HEX_SIXTEEN\n" +
+		"FORMAT_DECIMAL_MAX Format = 2147483647 // VK_FORMAT_DECIMAL_MAX\n" +
+		"FORMAT_MAX_ENUM Format = 0x7FFFFFFF // VK_FORMAT_MAX_ENUM\n"
+	if !strings.Contains(src, want) {
+		t.Errorf("want\n%s\nin\n%s", want, src)
+	}
+
+	// 0x7FFFFFFF and 2147483647 share one String case, or the switch would not compile
+	typeCheck(t, src, "", "fmt")
+	if !strings.Contains(src, "case 2147483647:\nreturn \"VK_FORMAT_DECIMAL_MAX\"") || strings.Contains(src, "return \"VK_FORMAT_MAX_ENUM\"") {
+		t.Errorf("want a single String case for 0x7FFFFFFF in\n%s", src)
+	}
+}
+
+func TestEnumOutputCompiles(t *testing.T) {
+	tr, vr := readTestRegistry(t, enumsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkResult", "VkFilter")
+
+	pkg := typeCheck(t, src, "", "fmt")
+	for _, name := range []string{"AllResult", "ParseResult", "AllFilter", "ParseFilter"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("%s is not declared", name)
+		}
+	}
+}
+
+func TestEnumAllValues(t *testing.T) {
+	tests := []struct {
+		enum string
+		want string
+	}{
+		{"VkResult", "var AllResult = []Result{\nERROR_OUT_OF_POOL_MEMORY,\nERROR_OUT_OF_HOST_MEMORY,\nResult(0),\nNOT_READY,\nSUBOPTIMAL_KHR,\n}"},
+		{"VkFilter", "var AllFilter = []Filter{\nFILTER_NEAREST,\nFILTER_LINEAR,\nFILTER_CUBIC_IMG,\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.enum, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, enumsFixture)
+			src := resolveAndPrint(t, tr, vr, tt.enum)
+			if !strings.Contains(src, tt.want) {
+				t.Errorf("want\n%s\nin\n%s", tt.want, src)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package def
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"regexp"
 	"strconv"
@@ -12,33 +13,56 @@ import (
 )
 
 // convertCLiteralToGo converts C-style literals to Go equivalents
-// Examples: "0.25f" → "0.25", "(~0U)" → "^uint32(0)", "(~1U)" → "^uint32(1)"
+// Examples: "0.25f" → "0.25", "(~0U)" → "^uint32(0)", "(~1U)" → "^uint32(1)", "(~0ULL)" → "^uint64(0)", "8U" → "8"
 func convertCLiteralToGo(cLiteral string) string {
-	// Remove trailing 'f' or 'F' from float literals
-	if strings.HasSuffix(cLiteral, "f") || strings.HasSuffix(cLiteral, "F") {
-		return strings.TrimSuffix(strings.TrimSuffix(cLiteral, "f"), "F")
+	lit := strings.TrimSpace(cLiteral)
+
+	if str, ok := convertCStringToGo(lit); ok {
+		return str
 	}
 
-	// Handle (~0U), (~1U), (~0ULL), etc.
-	reUint32 := regexp.MustCompile(`^\(~(\d+)U\)$`)
-	if match := reUint32.FindStringSubmatch(cLiteral); match != nil {
+	// Complemented unsigned values must be typed in Go, ^0 alone would be -1
+	if match := rxComplement.FindStringSubmatch(lit); match != nil {
+		if strings.EqualFold(match[2], "ULL") {
+			return fmt.Sprintf("^uint64(%s)", match[1])
+		}
 		return fmt.Sprintf("^uint32(%s)", match[1])
 	}
 
-	reUint64 := regexp.MustCompile(`^\(~(\d+)ULL\)$`)
-	if match := reUint64.FindStringSubmatch(cLiteral); match != nil {
-		return fmt.Sprintf("^uint64(%s)", match[1])
+	// Float literal, with a suffix
+	if match := rxFloatLiteral.FindStringSubmatch(lit); match != nil {
+		return match[1]
 	}
 
-	// Handle plain ~0U without parentheses
-	reUint32Plain := regexp.MustCompile(`^~(\d+)U$`)
-	if match := reUint32Plain.FindStringSubmatch(cLiteral); match != nil {
-		return fmt.Sprintf("^uint32(%s)", match[1])
+	// Integer literal with an unsigned or long suffix, which Go does not have. Plain negative values like VkResult's
+	// VK_ERROR_OUT_OF_HOST_MEMORY (-1) are already valid Go and are returned unchanged below.
+	if match := rxIntLiteral.FindStringSubmatch(lit); match != nil {
+		return match[1]
 	}
 
-	return cLiteral
+	return lit
+}
+
+// convertCStringToGo returns a Go string literal for a quoted C string value, like the VK_<NAME>_EXTENSION_NAME value
+// of each extension. The registry escapes the quotes as &quot;, which xmlquery has already decoded; any entities left in
+// the text are unescaped as well. ok is false if the value is not a string.
+func convertCStringToGo(cLiteral string) (goLiteral string, ok bool) {
+	lit := html.UnescapeString(strings.TrimSpace(cLiteral))
+	if len(lit) < 2 || !strings.HasPrefix(lit, `"`) || !strings.HasSuffix(lit, `"`) {
+		return "", false
+	}
+	if s, err := strconv.Unquote(lit); err == nil {
+		return strconv.Quote(s), true
+	}
+	return strconv.Quote(lit[1 : len(lit)-1]), true
 }
 
+var (
+	rxComplement   = regexp.MustCompile(`^\(?~(\d+)(U|ULL)\)?$`)
+	rxFloatLiteral = regexp.MustCompile(`^(\d+\.\d*(?:[eE][-+]?\d+)?|\d+[eE][-+]?\d+)[fF]$`)
+	rxIntLiteral   = regexp.MustCompile(`^(-?(?:0[xX][0-9a-fA-F]+|\d+))(?:[uU]|[uU]?[lL]{1,2})$`)
+)
+
 type enumValue struct {
 	genericValue
 
@@ -53,7 +77,8 @@ func (v *enumValue) ValueString() string {
 	if v.IsAlias() {
 		return v.resolvedAliasValue.PublicName()
 	} else {
-		if v.extNumber != 0 {
+		if v.extNumber != 0 && v.valueString == "" {
+			// Extension values are computed from the extension number and offset, negated when dir="-"
 			tmp := (1000000000 + (v.extNumber-1)*1000 + v.offset) * v.direction
 			return strconv.Itoa(tmp)
 		}
@@ -69,9 +94,20 @@ func (v *enumValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	if v.IsAlias() {
 		v.resolvedAliasValue = vr[v.aliasValueName]
+		if v.resolvedAliasValue == nil {
+			logrus.WithField("registry name", v.registryName).
+				WithField("alias name", v.aliasValueName).
+				Error("alias not found in registry while resolving value")
+			return rval
+		}
 		rval.MergeWith(v.resolvedAliasValue.Resolve(tr, vr))
 		v.valueString = RenameIdentifier(v.ValueString())
 
+		// Aliases are declared as NAME = ALIASED_NAME and must land in the same type bucket as the aliased value
+		if v.underlyingTypeName == "" {
+			v.underlyingTypeName = v.resolvedAliasValue.UnderlyingTypeName()
+		}
+
 		v.resolvedType = v.resolvedAliasValue.ResolvedType()
 		rval.MergeWith(v.resolvedType.Resolve(tr, vr))
 	} else {
@@ -89,12 +125,12 @@ func (v *enumValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 func (v *enumValue) PrintPublicDeclaration(w io.Writer) {
 	// Special case to allow SUCCESS Result to be treated as nil error. Must be separately defined as var, not const
 	if v.resolvedType.RegistryName() != "VkResult" || v.PublicName() != "SUCCESS" {
-		fmt.Fprintf(w, "%s %s = %s", v.PublicName(), v.resolvedType.PublicName(), v.ValueString())
-		if v.comment != "" {
-			fmt.Fprintf(w, " // %s\n", v.comment)
+		if v.printOptions.isTyped(v.resolvedType) {
+			fmt.Fprintf(w, "%s %s = %s", v.PublicName(), v.resolvedType.PublicName(), v.ValueString())
 		} else {
-			fmt.Fprintln(w)
+			fmt.Fprintf(w, "%s = %s", v.PublicName(), v.ValueString())
 		}
+		printValueComment(w, v.registryName, v.comment)
 	} else if v.IsAlias() {
 		fmt.Fprintf(w, "%s = %s\n", v.PublicName(), v.ValueString())
 	}
@@ -125,9 +161,27 @@ func ReadEnumValuesFromXML(doc *xmlquery.Node, td TypeDefiner, tr TypeRegistry,
 		coreVals := append(xmlquery.Find(groupNode, "/enum"))
 		extVals := xmlquery.Find(doc, fmt.Sprintf("//require/enum[@extends='%s']", td.RegistryName()))
 
+		// The enums block usually carries the description, rather than the type node
+		if et, ok := td.(*enumType); ok && et.comment == "" {
+			et.comment = groupNode.SelectAttr("comment")
+		}
+
 		switch groupNode.SelectAttr("type") {
 		case "bitmask":
 			td.(*enumType).isBitmaskType = true
+			// VkFlags64 bit values are declared with bitwidth="64"; 32 bits is the default
+			if groupNode.SelectAttr("bitwidth") == "64" {
+				td.(*enumType).bitWidth = 64
+			} else {
+				td.(*enumType).bitWidth = 32
+			}
+			// The flags type replaces this when it is read, but a flag bits type that no flags type refers to would
+			// otherwise be left as a 32-bit signed enum
+			if et := td.(*enumType); !et.IsAlias() && et.bitWidth == 64 {
+				et.underlyingTypeName = "VkFlags64"
+			} else if !et.IsAlias() {
+				et.underlyingTypeName = "VkFlags"
+			}
 			for _, enumNode := range coreVals {
 				valDef := NewBitmaskValueFromXML(td, enumNode)
 				valDef.isCore = true
@@ -202,7 +256,8 @@ func NewEnumValueFromXML(td TypeDefiner, elt *xmlquery.Node) *enumValue {
 			rval.direction = 1
 		}
 
-		// Only applies when an extension is promoted to core, otherwise the Extensioner needs to add the extension number to the value
+		// Only present when an extension is promoted to core or when a value is added by a different extension,
+		// otherwise the enclosing extension's number is used. See genericValue.SetExtensionNumber
 		extNumStr := elt.SelectAttr("extnumber")
 		if extNumStr != "" {
 			if rval.extNumber, err = strconv.Atoi(extNumStr); err != nil {
@@ -222,6 +277,8 @@ func NewEnumValueFromXML(td TypeDefiner, elt *xmlquery.Node) *enumValue {
 
 type extenValue struct {
 	enumValue
+
+	isString bool
 }
 
 func (v *extenValue) Category() TypeCategory { return CatExten }
@@ -239,6 +296,12 @@ func (v *extenValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	if v.IsAlias() {
 		v.resolvedAliasValue = vr[v.aliasValueName]
+		if v.resolvedAliasValue == nil {
+			logrus.WithField("registry name", v.registryName).
+				WithField("alias name", v.aliasValueName).
+				Error("alias not found in registry while resolving value")
+			return rval
+		}
 		rval.MergeWith(v.resolvedAliasValue.Resolve(tr, vr))
 		v.valueString = RenameIdentifier(v.ValueString())
 	}
@@ -250,12 +313,13 @@ func (v *extenValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 }
 
 func (v *extenValue) PrintPublicDeclaration(w io.Writer) {
-	if v.comment != "" {
-		fmt.Fprintf(w, "// %s\n", v.comment)
-	}
-
 	// Ignore explicit type, these values are untyped in the spec and the inferred type in Go is fine for our purpose
-	fmt.Fprintf(w, "%s = %s\n", v.PublicName(), v.ValueString())
+	if v.isString && v.printOptions.TypedAPIConstants {
+		fmt.Fprintf(w, "%s string = %s", v.PublicName(), v.ValueString())
+	} else {
+		fmt.Fprintf(w, "%s = %s", v.PublicName(), v.ValueString())
+	}
+	printValueComment(w, v.registryName, v.comment)
 }
 
 func (v *extenValue) ValueString() string {
@@ -272,7 +336,11 @@ func NewUntypedEnumValueFromXML(elt *xmlquery.Node) *extenValue {
 	alias := elt.SelectAttr("alias") // I don't think there are any alias entries for this category?
 	if alias == "" {
 		rval.registryName = elt.SelectAttr("name")
-		rval.valueString = convertCLiteralToGo(elt.SelectAttr("value"))
+		if str, ok := convertCStringToGo(elt.SelectAttr("value")); ok {
+			rval.valueString, rval.isString = str, true
+		} else {
+			rval.valueString = convertCLiteralToGo(elt.SelectAttr("value"))
+		}
 	} else {
 		rval.registryName = elt.SelectAttr("name")
 		rval.aliasValueName = alias
@@ -0,0 +1,167 @@
+package def
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestEnumValueFromOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		enumXML   string
+		extNumber int // number of the enclosing extension, 0 if none
+		want      string
+	}{
+		{"literal value", `<enum value="1" name="VK_NOT_READY"/>`, 0, "1"},
+		{"offset", `<enum extends="VkResult" offset="3" name="VK_SUBOPTIMAL_KHR"/>`, 2, "1000001003"},
+		// VK_KHR_maintenance1 is extension 70
+		{"negative direction", `<enum extends="VkResult" offset="0" dir="-" name="VK_ERROR_OUT_OF_POOL_MEMORY"/>`, 70, "-1000069000"},
+		{"extnumber overrides the extension", `<enum extends="VkResult" extnumber="70" offset="0" dir="-" name="VK_ERROR_OUT_OF_POOL_MEMORY"/>`, 1, "-1000069000"},
+		{"extnumber without an extension", `<enum extends="VkStructureType" extnumber="60" offset="11" name="VK_STRUCTURE_TYPE_DEVICE_GROUP_PRESENT_INFO_KHR"/>`, 0, "1000059011"},
+		{"value wins over offset", `<enum extends="VkResult" value="5" name="VK_FIVE"/>`, 70, "5"},
+	}
+	for _, tt := range tests {
+		doc, err := xmlquery.Parse(strings.NewReader(tt.enumXML))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		v := NewEnumValueFromXML(nil, xmlquery.FindOne(doc, "//enum"))
+		if tt.extNumber != 0 {
+			v.SetExtensionNumber(tt.extNumber)
+		}
+		if got := v.ValueString(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConvertCLiteralToGo(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"256", "256"},
+		{"-1", "-1"},
+		{"-1L", "-1"},
+		{"-0x10ULL", "-0x10"},
+		{"(~0U)", "^uint32(0)"},
+		{"(~1U)", "^uint32(1)"},
+		{"(~2U)", "^uint32(2)"},
+		{"(~0ULL)", "^uint64(0)"},
+		{"~0U", "^uint32(0)"},
+		{"1000.0F", "1000.0"},
+		{"1000.0f", "1000.0"},
+		{"1e3f", "1e3"},
+		{"0.5", "0.5"},
+		{"1U", "1"},
+		{"1ULL", "1"},
+		{"0x7FFFFFFF", "0x7FFFFFFF"},
+		{"0xFFFFFFFFU", "0xFFFFFFFF"},
+		{"0x7FFFFFFFFFFFFFFFULL", "0x7FFFFFFFFFFFFFFF"},
+		// The hex digits of 0xF must not be taken for a float suffix
+		{"0xF", "0xF"},
+		{`"VK_KHR_surface"`, `"VK_KHR_surface"`},
+		{" 42 ", "42"},
+	}
+	for _, tt := range tests {
+		if got := convertCLiteralToGo(tt.in); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAPIConstants(t *testing.T) {
+	tr, vr := readTestRegistry(t, apiConstantsFixture)
+	src := resolveAndPrint(t, tr, vr, apiConstantsTypes...)
+	pkg := typeCheck(t, src, "", "fmt")
+
+	// Each constant keeps its C type, so ~0U and ~0ULL are not both -1
+	tests := []struct {
+		name, wantType, wantValue string
+	}{
+		{"MAX_EXTENSION_NAME_SIZE", "untyped int", "256"},
+		{"REMAINING_MIP_LEVELS", "uint32", "4294967295"},
+		{"QUEUE_FAMILY_EXTERNAL", "uint32", "4294967294"},
+		{"WHOLE_SIZE", "uint64", "18446744073709551615"},
+		{"LOD_CLAMP_NONE", "untyped float", "1000"},
+	}
+	for _, tt := range tests {
+		c, ok := pkg.Scope().Lookup(tt.name).(*types.Const)
+		if !ok {
+			t.Errorf("%s is not a constant", tt.name)
+			continue
+		}
+		if got := c.Type().String(); got != tt.wantType {
+			t.Errorf("%s: type %s, want %s", tt.name, got, tt.wantType)
+		}
+		if got := c.Val().ExactString(); got != tt.wantValue {
+			t.Errorf("%s: value %s, want %s", tt.name, got, tt.wantValue)
+		}
+	}
+}
+
+func TestStringValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		enumXML  string
+		typed    bool
+		want     string // public declaration, without the trailing comment
+		wantText string // the Go string value, "" for numbers
+	}{
+		{"extension name", `<enum value="&quot;VK_KHR_swapchain&quot;" name="VK_KHR_SWAPCHAIN_EXTENSION_NAME"/>`, false,
+			`KHR_SWAPCHAIN_EXTENSION_NAME = "VK_KHR_swapchain"`, "VK_KHR_swapchain"},
+		{"typed extension name", `<enum value="&quot;VK_KHR_swapchain&quot;" name="VK_KHR_SWAPCHAIN_EXTENSION_NAME"/>`, true,
+			`KHR_SWAPCHAIN_EXTENSION_NAME string = "VK_KHR_swapchain"`, "VK_KHR_swapchain"},
+		// Entities left after XML decoding are unescaped as well
+		{"double escaped", `<enum value="&amp;quot;VK_EXT_debug_utils&amp;quot;" name="VK_EXT_DEBUG_UTILS_EXTENSION_NAME"/>`, false,
+			`EXT_DEBUG_UTILS_EXTENSION_NAME = "VK_EXT_debug_utils"`, "VK_EXT_debug_utils"},
+		{"spec version", `<enum value="70" name="VK_KHR_SWAPCHAIN_SPEC_VERSION"/>`, false,
+			`KHR_SWAPCHAIN_SPEC_VERSION = 70`, ""},
+	}
+	for _, tt := range tests {
+		doc, err := xmlquery.Parse(strings.NewReader(tt.enumXML))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		v := NewUntypedEnumValueFromXML(xmlquery.FindOne(doc, "//enum"))
+		v.SetPrintOptions(PrintOptions{TypedAPIConstants: tt.typed})
+		v.Resolve(nil, nil)
+
+		sb := &strings.Builder{}
+		v.PrintPublicDeclaration(sb)
+		if got := strings.SplitN(sb.String(), " //", 2)[0]; got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+		if tt.wantText != "" {
+			if text, err := strconv.Unquote(v.ValueString()); err != nil || text != tt.wantText {
+				t.Errorf("%s: value %s is not the Go string %q", tt.name, v.ValueString(), tt.wantText)
+			}
+		}
+	}
+}
+
+func TestConvertCStringToGo(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{`"VK_KHR_surface"`, `"VK_KHR_surface"`, true},
+		{`&quot;VK_KHR_surface&quot;`, `"VK_KHR_surface"`, true},
+		{` "VK_KHR_surface" `, `"VK_KHR_surface"`, true},
+		{`""`, `""`, true},
+		// Not valid Go escapes, the text between the quotes is kept as is
+		{`"C:\path"`, `"C:\\path"`, true},
+		{`"`, "", false},
+		{`42`, "", false},
+		{`(~0U)`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := convertCStringToGo(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %s, %v, want %s, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
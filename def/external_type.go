@@ -59,6 +59,8 @@ func ReadExternalTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, vr ValueRegis
 func NewExternalTypeFromXML(node *xmlquery.Node) *externalType {
 	rval := externalType{}
 	rval.registryName = node.SelectAttr("name")
+	// e.g. requires="vk_platform" for uint64_t, or "X11/Xlib.h" for Display
+	rval.requiresTypeName = node.SelectAttr("requires")
 
 	return &rval
 }
@@ -136,13 +138,22 @@ func (t *externalType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	}
 	t.internalName = t.publicName
 
+	// Marked before following requires, in case the required type leads back here
+	t.isResolved = true
+	is.ResolvedTypes[t.registryName] = t
+
 	if t.requiresTypeName != "" {
-		is.MergeWith(t.requiresType.Resolve(tr, vr))
+		// Include types are recorded in the resolved set, but are never printed
+		if t.requiresType = tr[t.requiresTypeName]; t.requiresType != nil {
+			is.MergeWith(t.requiresType.Resolve(tr, vr))
+			is.IncludeTypes[t.requiresTypeName] = true
+		} else {
+			logrus.WithField("registry name", t.registryName).
+				WithField("requires", t.requiresTypeName).
+				Debug("required type is not in the registry, assuming it is an external header")
+		}
 	}
 
-	is.ResolvedTypes[t.registryName] = t
-
-	t.isResolved = true
 	return is
 }
 
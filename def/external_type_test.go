@@ -0,0 +1,55 @@
+package def
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const requiresFixture = `<registry>
+<types>
+	<type category="include" name="vk_platform">#include "vk_platform.h"</type>
+	<type requires="vk_platform" name="uint64_t"/>
+	<type requires="X11/Xlib.h" name="Display"/>
+	<type requires="wayland-client.h" name="wl_display"/>
+	<type requires="VkLoopB" name="VkLoopA"/>
+	<type requires="VkLoopA" name="VkLoopB"/>
+</types>
+</registry>`
+
+func TestExternalTypeRequires(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantResolved string // sorted, comma separated
+		wantIncludes string
+	}{
+		{"uint64_t", "uint64_t,vk_platform", "vk_platform"},
+		// The headers are not in the registry, only the type itself is resolved
+		{"Display", "Display", ""},
+		{"wl_display", "wl_display", ""},
+		// A cycle of requires terminates; each type is recorded as required by the other
+		{"VkLoopA", "VkLoopA,VkLoopB", "VkLoopA,VkLoopB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, requiresFixture)
+			is := tr[tt.name].Resolve(tr, vr)
+
+			var resolved, includes []string
+			for k := range is.ResolvedTypes {
+				resolved = append(resolved, k)
+			}
+			for k := range is.IncludeTypes {
+				includes = append(includes, k)
+			}
+			sort.Strings(resolved)
+			sort.Strings(includes)
+			if got := strings.Join(resolved, ","); got != tt.wantResolved {
+				t.Errorf("resolved %s, want %s", got, tt.wantResolved)
+			}
+			if got := strings.Join(includes, ","); got != tt.wantIncludes {
+				t.Errorf("includes %s, want %s", got, tt.wantIncludes)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package def
+
+// structsFixture holds the struct types shared by the struct member tests
+const structsFixture = `<registry>
+<types>
+	<type category="enum" name="VkStructureType"/>
+	<type category="struct" name="VkBaseOutStructure">
+		<member><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>struct</type> <type>VkBaseOutStructure</type>* <name>pNext</name></member>
+	</type>
+	<type category="struct" name="VkTreeNode">
+		<member><type>VkTreeNode</type>* <name>pParent</name></member>
+		<member><type>VkTreeLeaf</type>* <name>pLeaf</name></member>
+	</type>
+	<type category="struct" name="VkTreeLeaf">
+		<member><type>VkTreeNode</type>* <name>pOwner</name></member>
+		<member><type>uint32_t</type> <name>value</name></member>
+	</type>
+	<type category="struct" name="VkExtent2D"><member><type>uint32_t</type> <name>width</name></member></type>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkBool32</name>;</type>
+	<type category="struct" name="VkExampleFeatures"><member><type>VkBool32</type> <name>enabled</name></member></type>
+	<type category="struct" name="VkRegionInfo">
+		<member>const <type>VkExtent2D</type>* <name>pRequired</name></member>
+		<member optional="true">const <type>VkExtent2D</type>* <name>pOptional</name></member>
+		<member noautovalidity="true">const <type>VkExtent2D</type>* <name>pUnchecked</name></member>
+		<member optional="true,false">const <type>VkExtent2D</type>* <name>pOptionalOuter</name></member>
+		<member optional="true"><type>uint32_t</type> <name>flags</name></member>
+		<member><type>uint32_t</type> <name>width</name></member>
+	</type>
+	<type category="struct" name="VkExtentList">
+		<member optional="true"><type>uint32_t</type> <name>extentCount</name></member>
+		<member optional="true" len="extentCount">const <type>VkExtent2D</type>* <name>pExtents</name></member>
+		<member><type>uint32_t</type> <name>requiredCount</name></member>
+		<member len="requiredCount">const <type>VkExtent2D</type>* <name>pRequired</name></member>
+	</type>
+</types>
+<enums name="VkStructureType" type="enum">
+	<enum value="0" name="VK_STRUCTURE_TYPE_APPLICATION_INFO"/>
+</enums>
+</registry>`
+
+// handlesFixture holds a tree of handles and their object types
+const handlesFixture = `<registry>
+<types>
+	<type category="enum" name="VkObjectType"/>
+	<type category="handle" objtypeenum="VK_OBJECT_TYPE_INSTANCE"><type>VK_DEFINE_HANDLE</type>(<name>VkInstance</name>)</type>
+	<type category="handle" parent="VkInstance" objtypeenum="VK_OBJECT_TYPE_SURFACE_KHR"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkSurfaceKHR</name>)</type>
+	<type category="handle" parent="VkInstance" objtypeenum="VK_OBJECT_TYPE_DEVICE"><type>VK_DEFINE_HANDLE</type>(<name>VkDevice</name>)</type>
+	<type category="handle" parent="VkDevice"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkDeviceMemory</name>)</type>
+	<type category="handle" parent="VkDevice" objtypeenum="VK_OBJECT_TYPE_COMMAND_POOL"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkCommandPool</name>)</type>
+	<type category="handle" parent="VkCommandPool" objtypeenum="VK_OBJECT_TYPE_COMMAND_BUFFER"><type>VK_DEFINE_HANDLE</type>(<name>VkCommandBuffer</name>)</type>
+	<type category="handle" parent="VkInstance" objtypeenum="VK_OBJECT_TYPE_DEBUG_REPORT_CALLBACK_EXT"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkDebugReportCallbackEXT</name>)</type>
+</types>
+<enums name="VkObjectType" type="enum">
+	<enum value="1" name="VK_OBJECT_TYPE_INSTANCE"/>
+	<enum value="3" name="VK_OBJECT_TYPE_DEVICE"/>
+	<enum value="6" name="VK_OBJECT_TYPE_COMMAND_BUFFER"/>
+	<enum value="8" name="VK_OBJECT_TYPE_DEVICE_MEMORY"/>
+	<enum value="25" name="VK_OBJECT_TYPE_COMMAND_POOL"/>
+	<enum value="1000000000" name="VK_OBJECT_TYPE_SURFACE_KHR"/>
+</enums>
+</registry>`
+
+// apiConstantsFixture holds API constants of each C type, and two enums whose values can be mixed up
+const apiConstantsFixture = `<registry>
+<types>
+	<type requires="vk_platform" name="uint32_t"/>
+	<type requires="vk_platform" name="uint64_t"/>
+	<type requires="vk_platform" name="float"/>
+	<type name="VkFilter" category="enum"/>
+	<type name="VkFormat" category="enum"/>
+</types>
+<enums name="API Constants">
+	<enum type="uint32_t" value="256" name="VK_MAX_EXTENSION_NAME_SIZE"/>
+	<enum type="uint32_t" value="(~0U)" name="VK_REMAINING_MIP_LEVELS"/>
+	<enum type="uint32_t" value="(~1U)" name="VK_QUEUE_FAMILY_EXTERNAL"/>
+	<enum type="uint64_t" value="(~0ULL)" name="VK_WHOLE_SIZE"/>
+	<enum type="float" value="1000.0F" name="VK_LOD_CLAMP_NONE"/>
+</enums>
+<enums name="VkFilter" type="enum">
+	<enum value="0" name="VK_FILTER_NEAREST"/>
+</enums>
+<enums name="VkFormat" type="enum">
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+</enums>
+</registry>`
+
+var apiConstantsTypes = []string{"uint32_t", "uint64_t", "float", "VkFilter", "VkFormat"}
+
+// commandsFixture holds commands with the parameter kinds that need special handling
+const commandsFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkBool32</name>;</type>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkBuffer</name>)</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkCommandBuffer</name>)</type>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkCommandPool</name>)</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkDevice</name>)</type>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkDeviceMemory</name>)</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkInstance</name>)</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkPhysicalDevice</name>)</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkQueue</name>)</type>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkSurfaceKHR</name>)</type>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkSwapchainKHR</name>)</type>
+	<type name="VkResult" category="enum"/>
+	<type category="struct" name="VkAllocationCallbacks"><member><type>void</type>* <name>pUserData</name></member></type>
+	<type category="struct" name="VkViewport"><member><type>float</type> <name>x</name></member></type>
+	<type category="struct" name="VkRect2D"><member><type>uint32_t</type> <name>x</name></member></type>
+</types>
+<enums name="VkResult" type="enum">
+	<enum value="0" name="VK_SUCCESS"/>
+	<enum value="1" name="VK_NOT_READY"/>
+	<enum value="2" name="VK_TIMEOUT"/>
+	<enum value="5" name="VK_INCOMPLETE"/>
+	<enum value="1000001003" name="VK_SUBOPTIMAL_KHR"/>
+	<enum value="-1" name="VK_ERROR_OUT_OF_HOST_MEMORY"/>
+	<enum value="-4" name="VK_ERROR_DEVICE_LOST"/>
+</enums>
+<commands>
+	<command>
+		<proto><type>void</type> <name>vkCmdSetViewport</name></proto>
+		<param><type>VkCommandBuffer</type> <name>commandBuffer</name></param>
+		<param><type>uint32_t</type> <name>viewportCount</name></param>
+		<param len="viewportCount">const <type>VkViewport</type>* <name>pViewports</name></param>
+	</command>
+	<command>
+		<proto><type>void</type> <name>vkCmdSetScissor</name></proto>
+		<param><type>VkCommandBuffer</type> <name>commandBuffer</name></param>
+		<param optional="true"><type>uint32_t</type> <name>scissorCount</name></param>
+		<param optional="true" len="scissorCount">const <type>VkRect2D</type>* <name>pScissors</name></param>
+	</command>
+	<command successcodes="VK_SUCCESS,VK_TIMEOUT,VK_NOT_READY,VK_SUBOPTIMAL_KHR" errorcodes="VK_ERROR_OUT_OF_HOST_MEMORY,VK_ERROR_DEVICE_LOST">
+		<proto><type>VkResult</type> <name>vkAcquireNextImageKHR</name></proto>
+		<param><type>VkDevice</type> <name>device</name></param>
+		<param><type>VkSwapchainKHR</type> <name>swapchain</name></param>
+		<param><type>uint64_t</type> <name>timeout</name></param>
+		<param><type>uint32_t</type>* <name>pImageIndex</name></param>
+	</command>
+	<command successcodes="VK_SUCCESS" errorcodes="VK_ERROR_OUT_OF_HOST_MEMORY,VK_ERROR_DEVICE_LOST">
+		<proto><type>VkResult</type> <name>vkQueueWaitIdle</name></proto>
+		<param><type>VkQueue</type> <name>queue</name></param>
+	</command>
+	<command>
+		<proto><type>void</type> <name>vkDestroyDevice</name></proto>
+		<param optional="true"><type>VkDevice</type> <name>device</name></param>
+	</command>
+	<command>
+		<proto><type>VkBool32</type> <name>vkGetPhysicalDeviceWin32PresentationSupportKHR</name></proto>
+		<param><type>VkPhysicalDevice</type> <name>physicalDevice</name></param>
+		<param><type>uint32_t</type> <name>queueFamilyIndex</name></param>
+	</command>
+	<command successcodes="VK_SUCCESS" errorcodes="VK_ERROR_OUT_OF_HOST_MEMORY">
+		<proto><type>VkResult</type> <name>vkGetPhysicalDeviceSurfaceSupportKHR</name></proto>
+		<param><type>VkPhysicalDevice</type> <name>physicalDevice</name></param>
+		<param><type>uint32_t</type> <name>queueFamilyIndex</name></param>
+		<param><type>VkSurfaceKHR</type> <name>surface</name></param>
+		<param><type>VkBool32</type>* <name>pSupported</name></param>
+	</command>
+	<command successcodes="VK_SUCCESS,VK_INCOMPLETE" errorcodes="VK_ERROR_OUT_OF_HOST_MEMORY">
+		<proto><type>VkResult</type> <name>vkEnumeratePhysicalDevices</name></proto>
+		<param><type>VkInstance</type> <name>instance</name></param>
+		<param optional="false,true"><type>uint32_t</type>* <name>pPhysicalDeviceCount</name></param>
+		<param optional="true" len="pPhysicalDeviceCount"><type>VkPhysicalDevice</type>* <name>pPhysicalDevices</name></param>
+	</command>
+	<command>
+		<proto><type>void</type> <name>vkDestroyBuffer</name></proto>
+		<param><type>VkDevice</type> <name>device</name></param>
+		<param optional="true"><type>VkBuffer</type> <name>buffer</name></param>
+		<param optional="true">const <type>VkAllocationCallbacks</type>* <name>pAllocator</name></param>
+	</command>
+	<command>
+		<proto><type>void</type> <name>vkFreeMemory</name></proto>
+		<param><type>VkDevice</type> <name>device</name></param>
+		<param optional="true"><type>VkDeviceMemory</type> <name>memory</name></param>
+		<param optional="true">const <type>VkAllocationCallbacks</type>* <name>pAllocator</name></param>
+	</command>
+	<command>
+		<proto><type>void</type> <name>vkFreeCommandBuffers</name></proto>
+		<param><type>VkDevice</type> <name>device</name></param>
+		<param><type>VkCommandPool</type> <name>commandPool</name></param>
+		<param><type>uint32_t</type> <name>commandBufferCount</name></param>
+		<param len="commandBufferCount">const <type>VkCommandBuffer</type>* <name>pCommandBuffers</name></param>
+	</command>
+</commands>
+</registry>`
+
+// flagsFixture holds 32 and 64 bit flags, with their bits and masks
+const flagsFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkFlags</name>;</type>
+	<type category="basetype">typedef <type>uint64_t</type> <name>VkFlags64</name>;</type>
+	<type requires="VkAccessFlagBits" category="bitmask">typedef <type>VkFlags</type> <name>VkAccessFlags</name>;</type>
+	<type bitvalues="VkAccessFlagBits2" category="bitmask">typedef <type>VkFlags64</type> <name>VkAccessFlags2</name>;</type>
+	<type name="VkAccessFlagBits" category="enum"/>
+	<type name="VkAccessFlagBits2" category="enum"/>
+	<type name="VkOrphanFlagBits2" category="enum"/>
+	<type requires="VkShaderStageFlagBits" category="bitmask">typedef <type>VkFlags</type> <name>VkShaderStageFlags</name>;</type>
+	<type name="VkShaderStageFlagBits" category="enum"/>
+</types>
+<enums name="VkAccessFlagBits" type="bitmask">
+	<enum bitpos="0" name="VK_ACCESS_INDIRECT_COMMAND_READ_BIT"/>
+	<enum bitpos="31" name="VK_ACCESS_HIGH_BIT"/>
+</enums>
+<enums name="VkAccessFlagBits2" type="bitmask" bitwidth="64">
+	<enum value="0" name="VK_ACCESS_2_NONE"/>
+	<enum bitpos="0" name="VK_ACCESS_2_INDIRECT_COMMAND_READ_BIT"/>
+	<enum bitpos="40" name="VK_ACCESS_2_SHADER_SAMPLED_READ_BIT"/>
+</enums>
+<enums name="VkOrphanFlagBits2" type="bitmask" bitwidth="64">
+	<enum bitpos="33" name="VK_ORPHAN_2_BIT"/>
+</enums>
+<enums name="VkShaderStageFlagBits" type="bitmask">
+	<enum bitpos="0" name="VK_SHADER_STAGE_VERTEX_BIT"/>
+	<enum bitpos="1" name="VK_SHADER_STAGE_TESSELLATION_CONTROL_BIT"/>
+	<enum bitpos="2" name="VK_SHADER_STAGE_TESSELLATION_EVALUATION_BIT"/>
+	<enum bitpos="3" name="VK_SHADER_STAGE_GEOMETRY_BIT"/>
+	<enum bitpos="4" name="VK_SHADER_STAGE_FRAGMENT_BIT"/>
+	<enum value="0x00000020" name="VK_SHADER_STAGE_COMPUTE_BIT"/>
+	<enum value="0x0000001F" name="VK_SHADER_STAGE_ALL_GRAPHICS"/>
+	<enum value="0x7FFFFFFFU" name="VK_SHADER_STAGE_ALL"/>
+	<enum value="0" name="VK_SHADER_STAGE_NONE"/>
+</enums>
+</registry>`
+
+// enumsFixture holds a signed enum with aliases, a regular enum and an enum with hex values
+const enumsFixture = `<registry>
+<types>
+	<type name="VkResult" category="enum"/>
+	<type name="VkFilter" category="enum"/>
+	<type name="VkFormat" category="enum"/>
+</types>
+<enums name="VkResult" type="enum">
+	<enum value="0" name="VK_SUCCESS"/>
+	<enum value="1" name="VK_NOT_READY"/>
+	<enum value="1000001003" name="VK_SUBOPTIMAL_KHR"/>
+	<enum value="-1" name="VK_ERROR_OUT_OF_HOST_MEMORY"/>
+	<enum value="-1000069000" name="VK_ERROR_OUT_OF_POOL_MEMORY"/>
+	<enum name="VK_ERROR_OUT_OF_POOL_MEMORY_KHR" alias="VK_ERROR_OUT_OF_POOL_MEMORY"/>
+</enums>
+<enums name="VkFilter" type="enum">
+	<enum value="0" name="VK_FILTER_NEAREST"/>
+	<enum value="1" name="VK_FILTER_LINEAR"/>
+	<enum value="1000015000" name="VK_FILTER_CUBIC_IMG"/>
+	<enum name="VK_FILTER_CUBIC_EXT" alias="VK_FILTER_CUBIC_IMG"/>
+</enums>
+<enums name="VkFormat" type="enum">
+	<enum value="0x7FFFFFFF" name="VK_FORMAT_MAX_ENUM"/>
+	<enum value="0x10" name="VK_FORMAT_HEX_SIXTEEN"/>
+	<enum value="9" name="VK_FORMAT_R8_UNORM"/>
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+	<enum value="2147483647" name="VK_FORMAT_DECIMAL_MAX"/>
+</enums>
+</registry>`
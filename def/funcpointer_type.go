@@ -0,0 +1,71 @@
+package def
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/sirupsen/logrus"
+)
+
+// funcPointerType is a PFN_* typedef. Go can't call or provide a C function pointer without a cgo trampoline for each
+// signature, so these are declared as unsafe.Pointer. The C signature is kept in the doc comment.
+type funcPointerType struct {
+	baseType
+
+	signature string
+}
+
+func (t *funcPointerType) Category() TypeCategory { return CatFuncPointer }
+
+func (t *funcPointerType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
+	if t.isResolved {
+		return NewIncludeSet()
+	}
+
+	rval := t.baseType.Resolve(tr, vr)
+	rval.ResolvedTypes[t.registryName] = t
+
+	return rval
+}
+
+func (t *funcPointerType) PrintPublicDeclaration(w io.Writer) {
+	t.PrintDocLink(w)
+	if t.signature != "" {
+		fmt.Fprintf(w, "//\n//\t%s\n", t.signature)
+	}
+	fmt.Fprintf(w, "type %s %s\n", t.PublicName(), t.resolvedUnderlyingType.InternalName())
+}
+
+func ReadFuncPointerTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, _ ValueRegistry, api string) {
+	queryString := fmt.Sprintf("//types/type[@category='funcpointer' and ((contains(@api,'%s') and not(@api='vulkansc')) or not(@api))]", api)
+
+	for _, node := range xmlquery.Find(doc, queryString) {
+		newType := NewFuncPointerTypeFromXML(node)
+		if newType == nil {
+			continue
+		}
+		if existing, found := tr[newType.RegistryName()]; found && existing.Category() != CatBasetype {
+			logrus.WithField("registry name", newType.RegistryName()).Warn("Overwriting function pointer type in registry")
+		}
+		tr[newType.RegistryName()] = newType
+	}
+}
+
+func NewFuncPointerTypeFromXML(node *xmlquery.Node) *funcPointerType {
+	// Older registries put the name directly under the type node, newer ones nest it in a <proto> element
+	nameNode := xmlquery.FindOne(node, ".//name")
+	if nameNode == nil {
+		logrus.WithField("type", node.OutputXML(true)).Warn("function pointer type has no name, skipping")
+		return nil
+	}
+
+	rval := funcPointerType{}
+	rval.registryName = nameNode.InnerText()
+	rval.comment = node.SelectAttr("comment")
+	rval.underlyingTypeName = "!pointer"
+	rval.signature = strings.Join(strings.Fields(node.InnerText()), " ")
+
+	return &rval
+}
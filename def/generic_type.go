@@ -3,6 +3,9 @@ package def
 import (
 	"fmt"
 	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
 // unresolvedType is a placeholder for types that don't exist in the registry
@@ -45,6 +48,15 @@ func (t *genericNamer) RegistryName() string { return t.registryName }
 func (t *genericNamer) PublicName() string   { return t.publicName }
 func (t *genericNamer) InternalName() string { return t.internalName }
 
+// SetPublicName changes the public name of the type after it has been resolved. See Renamer. Types declared the same
+// on both sides have their internal name changed with it.
+func (t *genericNamer) SetPublicName(name string) {
+	if t.internalName == t.publicName {
+		t.internalName = name
+	}
+	t.publicName = name
+}
+
 type genericType struct {
 	genericNamer
 	isResolved   bool
@@ -55,6 +67,8 @@ type genericType struct {
 	resolvedAliasType TypeDefiner
 
 	values []ValueDefiner
+
+	deprecationNote string
 }
 
 func (t *genericType) Category() TypeCategory { return CatNone }
@@ -72,7 +86,13 @@ func (t *genericType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	if t.aliasTypeName != "" {
 		t.resolvedAliasType = tr[t.aliasTypeName]
-		rval.MergeWith(t.resolvedAliasType.Resolve(tr, vr))
+		if t.resolvedAliasType == nil {
+			logrus.WithField("registry name", t.registryName).
+				WithField("alias name", t.aliasTypeName).
+				Error("alias not found in registry while resolving type")
+		} else {
+			rval.MergeWith(t.resolvedAliasType.Resolve(tr, vr))
+		}
 	}
 
 	rval.ResolvedTypes[t.registryName] = t
@@ -87,6 +107,13 @@ func (t *genericType) SetAliasType(td TypeDefiner) {
 
 func (t *genericType) IsAlias() bool { return t.resolvedAliasType != nil }
 
+func (t *genericType) DanglingAlias() string {
+	if t.aliasTypeName != "" && t.resolvedAliasType == nil {
+		return t.aliasTypeName
+	}
+	return ""
+}
+
 func (t *genericType) AllValues() []ValueDefiner {
 	return t.values
 }
@@ -102,7 +129,7 @@ func (t *genericType) PushValue(vd ValueDefiner) {
 }
 
 func (t *genericType) AppendValues(vals ValueRegistry) {
-	for _, v := range vals {
+	for _, v := range vals.Sorted() {
 		t.values = append(t.values, v)
 	}
 }
@@ -135,7 +162,23 @@ func (t *genericType) PrintTranslateToInternal(w io.Writer, inputVar, outputVar
 func (t *genericType) PrintDocLink(w io.Writer) {
 	fmt.Fprintf(w, "// %s: ", t.PublicName())
 	if t.comment != "" {
-		fmt.Fprint(w, t.comment, "\n// ")
+		fmt.Fprint(w, strings.ReplaceAll(strings.TrimSpace(t.comment), "\n", "\n// "), "\n// ")
 	}
 	fmt.Fprintf(w, "See https://www.khronos.org/registry/vulkan/specs/1.3-extensions/man/html/%s.html\n", t.RegistryName())
+	t.printDeprecation(w, true)
+}
+
+// SetDeprecated marks the type as deprecated. note is printed after "Deprecated: " in the type's doc comment.
+func (t *genericType) SetDeprecated(note string) { t.deprecationNote = note }
+
+// printDeprecation writes the Deprecated paragraph of a doc comment, if the type is deprecated. continued indicates
+// that the paragraph follows other doc comment lines.
+func (t *genericType) printDeprecation(w io.Writer, continued bool) {
+	if t.deprecationNote == "" {
+		return
+	}
+	if continued {
+		fmt.Fprint(w, "//\n")
+	}
+	fmt.Fprintf(w, "// Deprecated: %s\n", t.deprecationNote)
 }
@@ -3,11 +3,16 @@ package def
 import (
 	"fmt"
 	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
 type genericValue struct {
 	registryName string
 	valueString  string
+	// publicName replaces the name derived from registryName if set, see SetPublicName
+	publicName string
 
 	underlyingTypeName string
 	resolvedType       TypeDefiner
@@ -21,10 +26,21 @@ type genericValue struct {
 
 	isResolved bool
 	isCore     bool
+
+	printOptions PrintOptions
 }
 
 func (v *genericValue) RegistryName() string { return v.registryName }
-func (v *genericValue) PublicName() string   { return RenameIdentifier(v.registryName) }
+func (v *genericValue) PublicName() string {
+	if v.publicName != "" {
+		return v.publicName
+	}
+	return RenameIdentifier(v.registryName)
+}
+
+// SetPublicName changes the name the value is published as, e.g. to a short value name or to resolve a collision. See
+// Renamer.
+func (v *genericValue) SetPublicName(name string) { v.publicName = name }
 func (v *genericValue) ValueString() string {
 	if v.IsAlias() {
 		return v.resolvedAliasValue.PublicName()
@@ -33,7 +49,13 @@ func (v *genericValue) ValueString() string {
 	}
 }
 
-func (v *genericValue) SetExtensionNumber(extNum int) { v.extNumber = extNum }
+// SetExtensionNumber sets the number of the extension that defines this value. An extnumber attribute read from
+// the registry takes precedence, so this is only a fallback for values that did not specify one.
+func (v *genericValue) SetExtensionNumber(extNum int) {
+	if v.extNumber == 0 {
+		v.extNumber = extNum
+	}
+}
 
 func (v *genericValue) UnderlyingTypeName() string { return v.underlyingTypeName }
 
@@ -42,6 +64,16 @@ func (v *genericValue) ResolvedType() TypeDefiner { return v.resolvedType }
 func (v *genericValue) IsAlias() bool { return v.aliasValueName != "" }
 func (v *genericValue) IsCore() bool  { return v.isCore }
 
+// resolvedAlias gives canonicalValue access to the aliased value of any value embedding genericValue
+func (v *genericValue) resolvedAlias() ValueDefiner { return v.resolvedAliasValue }
+
+func (v *genericValue) DanglingAlias() string {
+	if v.aliasValueName != "" && v.resolvedAliasValue == nil {
+		return v.aliasValueName
+	}
+	return ""
+}
+
 func (v *genericValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	if v.isResolved {
 		return NewIncludeSet()
@@ -51,6 +83,12 @@ func (v *genericValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	if v.IsAlias() {
 		v.resolvedAliasValue = vr[v.aliasValueName]
+		if v.resolvedAliasValue == nil {
+			logrus.WithField("registry name", v.registryName).
+				WithField("alias name", v.aliasValueName).
+				Error("alias not found in registry while resolving value")
+			return NewIncludeSet()
+		}
 		v.resolvedAliasValue.Resolve(tr, vr)
 		v.valueString = RenameIdentifier(v.ValueString())
 
@@ -66,6 +104,19 @@ func (v *genericValue) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	return rval
 }
 
+// SetPrintOptions sets the options the value is declared with. See PrintConfigurer.
+func (v *genericValue) SetPrintOptions(opts PrintOptions) { v.printOptions = opts }
+
+// printValueComment ends a value declaration with a line comment giving the registry name, so that go doc shows the
+// mapping back to the spec, followed by the registry's comment, if any.
+func printValueComment(w io.Writer, registryName, comment string) {
+	if comment != "" {
+		fmt.Fprintf(w, " // %s: %s\n", registryName, strings.Join(strings.Fields(comment), " "))
+	} else {
+		fmt.Fprintf(w, " // %s\n", registryName)
+	}
+}
+
 func (v *genericValue) PrintPublicDeclaration(w io.Writer) {
 	fmt.Fprintf(w, "%s %s = %s\n", v.PublicName(), v.resolvedType.PublicName(), v.ValueString())
 }
@@ -0,0 +1,55 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypedValues(t *testing.T) {
+	tests := []struct {
+		name                  string
+		typedEnums, typedAPIs bool
+		want                  []string
+		// Untyped values trade the check that a value belongs to its enum for use with any numeric type
+		compiles, notCompiles []string
+	}{
+		{"default", true, false,
+			[]string{"FILTER_NEAREST Filter = 0", "MAX_EXTENSION_NAME_SIZE = 256", "WHOLE_SIZE = ^uint64(0)"},
+			[]string{"var _ int = MAX_EXTENSION_NAME_SIZE", "var _ [MAX_EXTENSION_NAME_SIZE]byte"},
+			[]string{"var _ Filter = FORMAT_UNDEFINED", "var _ int = FILTER_NEAREST"},
+		},
+		{"untyped enums", false, false,
+			[]string{"FILTER_NEAREST = 0", "FORMAT_UNDEFINED = 0"},
+			[]string{"var _ Filter = FORMAT_UNDEFINED", "var _ int = FILTER_NEAREST"},
+			nil,
+		},
+		{"typed constants", true, true,
+			[]string{"MAX_EXTENSION_NAME_SIZE uint32 = 256", "WHOLE_SIZE uint64 = ^uint64(0)", "LOD_CLAMP_NONE float32 = 1000.0"},
+			[]string{"var _ uint32 = REMAINING_MIP_LEVELS", "var _ [MAX_EXTENSION_NAME_SIZE]byte"},
+			[]string{"var _ int = MAX_EXTENSION_NAME_SIZE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, apiConstantsFixture)
+			SetPrintOptions(tr, vr, PrintOptions{UntypedEnumValues: !tt.typedEnums, TypedAPIConstants: tt.typedAPIs})
+			src := resolveAndPrint(t, tr, vr, apiConstantsTypes...)
+			for _, want := range tt.want {
+				if !strings.Contains(src, want) {
+					t.Errorf("want %q in\n%s", want, src)
+				}
+			}
+
+			for _, stmt := range tt.compiles {
+				if _, err := checkGenerated(src, "\n"+stmt+"\n", "fmt"); err != nil {
+					t.Errorf("%s does not compile: %v", stmt, err)
+				}
+			}
+			for _, stmt := range tt.notCompiles {
+				if _, err := checkGenerated(src, "\n"+stmt+"\n", "fmt"); err == nil {
+					t.Errorf("%s compiles", stmt)
+				}
+			}
+		})
+	}
+}
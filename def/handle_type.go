@@ -4,14 +4,25 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/iancoleman/strcase"
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
 type handleType struct {
 	internalType
+
+	// objectTypeValue is the VkObjectType value for this handle, which debug utils take alongside a handle.
+	// objectTypeName is read from the objtypeenum attribute.
+	objectTypeName  string
+	objectTypeValue ValueDefiner
+
+	// parentTypeNames are the handles this one is created from or owned by, from the comma separated parent attribute
+	parentTypeNames []string
+	parentTypes     []*handleType
 }
 
 func (t *handleType) Category() TypeCategory { return CatHandle }
@@ -23,6 +34,36 @@ func (t *handleType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	rval := t.internalType.Resolve(tr, vr)
 
+	for _, p := range t.parentTypeNames {
+		parent, ok := tr[p].(*handleType)
+		if !ok {
+			logrus.WithField("registry name", t.registryName).
+				WithField("parent", p).
+				Warn("parent of handle is not a handle type in the registry")
+			continue
+		}
+		rval.MergeWith(parent.Resolve(tr, vr))
+		rval.IncludeTypes[p] = true
+		t.parentTypes = append(t.parentTypes, parent)
+	}
+
+	// Aliases share the object type of the aliased handle
+	if !t.IsAlias() && strings.HasPrefix(t.registryName, "Vk") {
+		objTypeName := t.objectTypeName
+		if objTypeName == "" {
+			// Registries without objtypeenum are matched by name, VK_OBJECT_TYPE_DEVICE_MEMORY for VkDeviceMemory
+			objTypeName = "VK_OBJECT_TYPE_" + strcase.ToScreamingSnake(strings.TrimPrefix(t.registryName, "Vk"))
+		}
+		if t.objectTypeValue = vr[objTypeName]; t.objectTypeValue != nil {
+			rval.MergeWith(t.objectTypeValue.Resolve(tr, vr))
+			rval.IncludeValues[objTypeName] = true
+		} else if t.objectTypeName != "" {
+			logrus.WithField("registry name", t.registryName).
+				WithField("objtypeenum", t.objectTypeName).
+				Warn("object type value for handle is not in the registry")
+		}
+	}
+
 	rval.ResolvedTypes[t.registryName] = t
 
 	t.isResolved = true
@@ -41,6 +82,26 @@ func (t *handleType) PrintPublicDeclaration(w io.Writer) {
 		}
 		fmt.Fprint(w, ")\n\n")
 	}
+
+	if t.objectTypeValue != nil {
+		fmt.Fprintf(w, "// ObjectType returns %s, for naming or tagging the object through debug utils\n", t.objectTypeValue.PublicName())
+		fmt.Fprintf(w, "func (h %s) ObjectType() %s { return %s }\n\n",
+			t.PublicName(), t.objectTypeValue.ResolvedType().PublicName(), t.objectTypeValue.PublicName())
+
+		var parents []string
+		for _, p := range t.parentTypes {
+			if p.objectTypeValue != nil {
+				parents = append(parents, p.objectTypeValue.PublicName())
+			}
+		}
+		fmt.Fprint(w, "// ParentObjectTypes returns the object types of the handles this handle is created from, or nil if it has no\n// parent\n")
+		fmt.Fprintf(w, "func (h %s) ParentObjectTypes() []%s {\n", t.PublicName(), t.objectTypeValue.ResolvedType().PublicName())
+		if len(parents) == 0 {
+			fmt.Fprint(w, "return nil\n}\n\n")
+		} else {
+			fmt.Fprintf(w, "return []%s{%s}\n}\n\n", t.objectTypeValue.ResolvedType().PublicName(), strings.Join(parents, ", "))
+		}
+	}
 }
 
 func ReadHandleTypesFromXML(doc *xmlquery.Node, tr TypeRegistry, _ ValueRegistry, api string) {
@@ -64,9 +125,14 @@ func NewHandleTypeFromXML(node *xmlquery.Node) TypeDefiner {
 	} else {
 		rval.registryName = xmlquery.FindOne(node, "name").InnerText()
 		rval.underlyingTypeName = xmlquery.FindOne(node, "type").InnerText()
+		rval.objectTypeName = node.SelectAttr("objtypeenum")
+		if parent := node.SelectAttr("parent"); parent != "" {
+			rval.parentTypeNames = strings.Split(parent, ",")
+		}
 	}
 
 	rval.publicName = RenameIdentifier(rval.registryName)
+	rval.comment = node.SelectAttr("comment")
 
 	return &rval
 }
@@ -0,0 +1,76 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandleObjectType(t *testing.T) {
+	tests := []struct {
+		handle    string
+		wantValue string // "" if the handle gets no ObjectType method
+	}{
+		{"VkSurfaceKHR", "VK_OBJECT_TYPE_SURFACE_KHR"},
+		{"VkInstance", "VK_OBJECT_TYPE_INSTANCE"},
+		// No objtypeenum, matched by name
+		{"VkDeviceMemory", "VK_OBJECT_TYPE_DEVICE_MEMORY"},
+		// objtypeenum names a value that is not in the registry
+		{"VkDebugReportCallbackEXT", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.handle, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, handlesFixture)
+			is := tr[tt.handle].Resolve(tr, vr)
+
+			// The value is a dependency of the handle
+			if tt.wantValue != "" && !is.IncludeValues[tt.wantValue] {
+				t.Errorf("%s is not included by the handle", tt.wantValue)
+			}
+
+			sb := &strings.Builder{}
+			tr[tt.handle].PrintPublicDeclaration(sb)
+			method := "ObjectType() ObjectType { return "
+			if tt.wantValue == "" {
+				if strings.Contains(sb.String(), method) {
+					t.Errorf("unexpected ObjectType method in\n%s", sb.String())
+				}
+				return
+			}
+			want := method + strings.TrimPrefix(tt.wantValue, "VK_") + " }"
+			if !strings.Contains(sb.String(), want) {
+				t.Errorf("want %q in\n%s", want, sb.String())
+			}
+		})
+	}
+}
+
+func TestHandleParents(t *testing.T) {
+	tr, vr := readTestRegistry(t, handlesFixture)
+
+	// Requiring the child pulls in its parents, up to the instance
+	is := tr["VkCommandBuffer"].Resolve(tr, vr)
+	for _, n := range []string{"VkCommandPool", "VkDevice", "VkInstance"} {
+		if is.ResolvedTypes[n] == nil {
+			t.Errorf("parent %s was not resolved", n)
+		}
+	}
+
+	names := []string{"VkObjectType", "VkInstance", "VkSurfaceKHR", "VkDevice", "VkDeviceMemory", "VkCommandPool", "VkCommandBuffer"}
+	src := resolveAndPrint(t, tr, vr, names...)
+	typeCheck(t, src, "\ntype handle uintptr\ntype nonDispatchableHandle uint64\n", "fmt")
+
+	tests := []struct {
+		handle, wantBody string
+	}{
+		{"CommandBuffer", "return []ObjectType{OBJECT_TYPE_COMMAND_POOL}"},
+		{"SurfaceKHR", "return []ObjectType{OBJECT_TYPE_INSTANCE}"},
+		{"DeviceMemory", "return []ObjectType{OBJECT_TYPE_DEVICE}"},
+		{"Instance", "return nil"},
+	}
+	for _, tt := range tests {
+		want := "func (h " + tt.handle + ") ParentObjectTypes() []ObjectType {\n" + tt.wantBody + "\n}"
+		if !strings.Contains(src, want) {
+			t.Errorf("%s: want %q in\n%s", tt.handle, want, src)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package def
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	logrus.SetLevel(logrus.ErrorLevel)
+}
+
+// readTestRegistry reads every type category from an inline registry document, along with the repository's
+// exceptions.json, so that fixtures can use the C types and VkBool32 without declaring them
+func readTestRegistry(t *testing.T, registryXML string) (TypeRegistry, ValueRegistry) {
+	t.Helper()
+
+	doc, err := xmlquery.Parse(strings.NewReader(registryXML))
+	if err != nil {
+		t.Fatalf("could not parse fixture: %v", err)
+	}
+	exceptionsBytes, err := os.ReadFile("../exceptions.json")
+	if err != nil {
+		t.Fatalf("could not read exceptions.json: %v", err)
+	}
+	exceptions := gjson.ParseBytes(exceptionsBytes)
+
+	tr, vr := make(TypeRegistry), make(ValueRegistry)
+	for tc := CatNone; tc < CatMaximum; tc++ {
+		xml, json := tc.ReadFns()
+		if xml != nil {
+			xml(doc, tr, vr, "vulkan")
+		}
+		if json != nil {
+			json(exceptions, tr, vr)
+		}
+	}
+	return tr, vr
+}
+
+// resolveAndPrint resolves the named types along with their values and returns their public and internal declarations
+func resolveAndPrint(t *testing.T, tr TypeRegistry, vr ValueRegistry, names ...string) string {
+	t.Helper()
+
+	sb := &strings.Builder{}
+	for _, n := range names {
+		td := tr[n]
+		if td == nil {
+			t.Fatalf("%s is not in the registry", n)
+		}
+		td.Resolve(tr, vr)
+
+		// Feature resolution normally collects the type's values, see Feature.Resolve
+		vals := make(ValueRegistry)
+		for k, v := range vr {
+			if v.UnderlyingTypeName() == n {
+				v.Resolve(tr, vr)
+				vals[k] = v
+			}
+		}
+		td.AppendValues(vals)
+
+		td.PrintPublicDeclaration(sb)
+		td.PrintInternalDeclaration(sb)
+	}
+	return sb.String()
+}
+
+// typeCheck type checks generated declarations as the single file of package vk, returning the package so that tests
+// can look up the declared identifiers. extra is added to the file as is, for declarations that would come from other
+// generated or static files.
+func typeCheck(t *testing.T, src, extra string, imports ...string) *types.Package {
+	t.Helper()
+
+	pkg, err := checkGenerated(src, extra, imports...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return pkg
+}
+
+// checkGenerated type checks like typeCheck but returns the error, for tests that expect generated code not to compile
+func checkGenerated(src, extra string, imports ...string) (*types.Package, error) {
+	sb := &strings.Builder{}
+	sb.WriteString("package vk\n\n")
+	for _, imp := range imports {
+		sb.WriteString("import \"" + imp + "\"\n")
+	}
+	sb.WriteString(src)
+	sb.WriteString(extra)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", sb.String(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not parse: %v\n%s", err, sb.String())
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("vk", fset, []*ast.File{file}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not compile: %v\n%s", err, sb.String())
+	}
+	return pkg, nil
+}
+
+// runGenerated builds and runs generated declarations as package main, with mainBody as the body of func main, and
+// returns the output. It is skipped if the go command is not available.
+func runGenerated(t *testing.T, src, mainBody string, imports ...string) string {
+	t.Helper()
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("package main\n\n")
+	for _, imp := range imports {
+		sb.WriteString("import \"" + imp + "\"\n")
+	}
+	sb.WriteString(src)
+	sb.WriteString("\nfunc main() {\n" + mainBody + "\n}\n")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module generated\n\ngo 1.20\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(sb.String()), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code did not run: %v\n%s\n%s", err, out, sb.String())
+	}
+	return string(out)
+}
+
+// readStatic returns the declarations in a file of static_include, without the package clause, to be run along with
+// the generated code that uses them
+func readStatic(t *testing.T, name string) string {
+	t.Helper()
+
+	src, err := os.ReadFile(filepath.Join("../static_include", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Replace(string(src), "package vk\n", "", 1)
+}
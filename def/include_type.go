@@ -20,6 +20,12 @@ func (t *includeType) Category() TypeCategory             { return CatInclude }
 func (t *includeType) IsIdenticalPublicAndInternal() bool { return true }
 
 func (t *includeType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
+	if t.isResolved {
+		return NewIncludeSet()
+	}
+	// Types included by this header may require it in turn
+	t.isResolved = true
+
 	t.resolvedIncludedTypes = make(TypeRegistry)
 	rval := NewIncludeSet()
 
@@ -32,7 +38,6 @@ func (t *includeType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	rval.ResolvedTypes[t.registryName] = t
 
-	t.isResolved = true
 	return rval
 }
 
@@ -2,7 +2,10 @@ package def
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
 var trimRx = regexp.MustCompile(`(\**)(Vk|VK_|vk)?(.+)`)
@@ -44,3 +47,141 @@ func RenameIdentifier(s string) string {
 		return s
 	}
 }
+
+// NameTransform derives the Go identifier a type or value is published as from its registry name. For a value, category
+// is the category of the value's type, or CatNone for untyped values like extension names.
+type NameTransform func(vkName string, category TypeCategory) string
+
+// DefaultNameTransform is the naming used when no transform is given, see RenameIdentifier
+func DefaultNameTransform(vkName string, _ TypeCategory) string { return RenameIdentifier(vkName) }
+
+// StaticRegistryNames are the types and values that the static files (static_include) refer to by their default Go
+// identifier, e.g. Result in the Error method. A NameTransform must not rename them.
+var StaticRegistryNames = map[string]bool{
+	"VkBool32": true, "VkResult": true, "VkStructureType": true,
+	"VK_FALSE": true, "VK_TRUE": true, "VK_SUCCESS": true, "VK_INCOMPLETE": true,
+}
+
+// StaticIdentifiers are declared at the top level of the static files, so no type or value may be renamed to one of
+// them
+var StaticIdentifiers = map[string]bool{
+	"AppendNext": true, "CString": true, "CStringFromBytes": true, "ChainNode": true, "Enumerate": true,
+	"FromBool": true, "GoString": true, "GoStringFromArray": true, "GoStringN": true, "Goifier": true,
+	"LinkChain": true, "MemCopy": true, "MemCopyObj": true, "MemCopySlice": true,
+	"OverrideDefaultVulkanLibrary": true, "StructureTypeOf": true, "Vulkanizer": true,
+	"apiVersionMajor": true, "apiVersionMinor": true, "apiVersionPatch": true, "apiVersionVariant": true,
+	"chainHeader": true, "deprecatedMakeVersion": true, "deprecatedVersionMajor": true,
+	"deprecatedVersionMinor": true, "deprecatedVersionPatch": true, "dlHandle": true, "execTrampoline": true,
+	"initDlHandle": true, "makeApiVersion": true, "makeVersion": true, "max": true, "nullTermBytesToString": true,
+	"overrideLibName": true, "sl": true, "stringToNullTermBytes": true, "structureTypeOf": true,
+	"sys_stringToBytePointer": true, "translateInternal_Bool32": true, "translatePublic_Bool32": true,
+	"vkCommand": true,
+}
+
+var rxTypeTag = regexp.MustCompile(`[A-Z]{2,}$`)
+var rxCamelWord = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// enumValuePrefix derives the prefix shared by an enum's values from the type name, e.g. VkImageTiling gives
+// VK_IMAGE_TILING_ and VkSurfaceTransformFlagBitsKHR gives VK_SURFACE_TRANSFORM_.
+func enumValuePrefix(typeName string) string {
+	s := rxTypeTag.ReplaceAllString(typeName, "")
+	s = strings.TrimSuffix(s, "FlagBits2")
+	s = strings.TrimSuffix(s, "FlagBits")
+	s = rxCamelWord.ReplaceAllString(s, "${1}_${2}")
+	return strings.ToUpper(s) + "_"
+}
+
+// AssignShortValueNames strips the shared prefix from the values of each enum, so VK_FORMAT_R8G8B8A8_UNORM is
+// published as R8G8B8A8_UNORM instead of FORMAT_R8G8B8A8_UNORM. It must be called after all values, including those
+// added by extensions, are in the registry and before anything is resolved.
+//
+// A value keeps its regular name if it does not start with the prefix, if stripping would leave a leading digit
+// (VK_IMAGE_TYPE_2D), or if the short name would collide with any other type or value in the package. Short names are
+// set on the values themselves, so other registries are not affected.
+func AssignShortValueNames(tr TypeRegistry, vr ValueRegistry) {
+	candidates := make(map[string]string)
+	for _, td := range tr {
+		et, ok := td.(*enumType)
+		if !ok || et.aliasTypeName != "" {
+			continue
+		}
+		prefix := enumValuePrefix(et.RegistryName())
+		for k, v := range vr {
+			if v.UnderlyingTypeName() != et.RegistryName() || !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			short := strings.TrimPrefix(k, prefix)
+			if short == "" || (short[0] >= '0' && short[0] <= '9') {
+				continue
+			}
+			candidates[k] = short
+		}
+	}
+
+	// Count every name that will be published, using the short name where one is proposed
+	used := make(map[string]int)
+	for _, td := range tr {
+		// Most public type names are only assigned during Resolve
+		if name := td.PublicName(); name != "" {
+			used[name]++
+		} else {
+			used[RenameIdentifier(td.RegistryName())]++
+		}
+	}
+	for k, v := range vr {
+		if short, ok := candidates[k]; ok {
+			used[short]++
+		} else {
+			used[v.PublicName()]++
+		}
+	}
+
+	for k, short := range candidates {
+		if used[short] > 1 {
+			logrus.WithField("registry name", k).
+				WithField("short name", short).
+				Debug("short value name collides with another identifier, keeping the full name")
+			continue
+		}
+		if r, ok := vr[k].(Renamer); ok {
+			r.SetPublicName(short)
+		}
+	}
+}
+
+// NamePair is a registry name and the Go identifier it will be published as
+type NamePair struct {
+	RegistryName, GoName string
+}
+
+// ResolveNameCollisions finds entries whose Go name was already claimed by an earlier entry in names and returns a
+// replacement for each, keyed by registry name. The first entry keeps its name and later ones get the suffix _2, _3,
+// and so on, skipping any suffixed name that is itself in use. The result only depends on the order of names, so
+// callers should sort their input.
+func ResolveNameCollisions(names []NamePair) map[string]string {
+	rval := make(map[string]string)
+
+	used := make(map[string]bool, len(names))
+	for _, n := range names {
+		used[n.GoName] = true
+	}
+
+	claimed := make(map[string]bool, len(names))
+	for _, n := range names {
+		if !claimed[n.GoName] {
+			claimed[n.GoName] = true
+			continue
+		}
+		for i := 2; ; i++ {
+			candidate := n.GoName + "_" + strconv.Itoa(i)
+			if !used[candidate] {
+				used[candidate] = true
+				claimed[candidate] = true
+				rval[n.RegistryName] = candidate
+				break
+			}
+		}
+	}
+
+	return rval
+}
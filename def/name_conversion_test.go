@@ -0,0 +1,27 @@
+package def
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveNameCollisions(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []NamePair
+		want  map[string]string
+	}{
+		{"no collisions", []NamePair{{"VkA", "A"}, {"VkB", "B"}}, map[string]string{}},
+		{"later entry is renamed", []NamePair{{"VkA", "A"}, {"VK_A", "A"}}, map[string]string{"VK_A": "A_2"}},
+		{"suffixes count up", []NamePair{{"VkA", "A"}, {"VK_A", "A"}, {"VK_A_KHR", "A"}}, map[string]string{"VK_A": "A_2", "VK_A_KHR": "A_3"}},
+		{"suffix in use is skipped", []NamePair{{"VkA", "A"}, {"VkA2", "A_2"}, {"VK_A", "A"}}, map[string]string{"VK_A": "A_3"}},
+		{"suffix in use later in the list is skipped", []NamePair{{"VkA", "A"}, {"VK_A", "A"}, {"VkA2", "A_2"}}, map[string]string{"VK_A": "A_3"}},
+		{"order decides", []NamePair{{"VK_A", "A"}, {"VkA", "A"}}, map[string]string{"VkA": "A_2"}},
+	}
+	for _, tt := range tests {
+		got := ResolveNameCollisions(tt.names)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
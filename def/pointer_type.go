@@ -89,6 +89,13 @@ func (t *pointerType) PrintVulkanizeContent(forMember *structMember, preamble io
 	structMemberAssignment = "0 /* TODO POINTER NOT HANDLED */"
 
 	if t.isArrayPointer() {
+		// An empty slice is passed as nil, unless the member is not optional. Then a non-nil empty slice is kept as a
+		// non-nil pointer, as for command parameters, see commandParam.sliceGuard.
+		guard := fmt.Sprintf("len(s.%s) > 0", forMember.PublicName())
+		if !forMember.IsOptional() {
+			guard = fmt.Sprintf("s.%s != nil", forMember.PublicName())
+		}
+
 		if t.lenSpec == "null-terminated" {
 			// Special case for strings, just give back the result of TranslateInternal
 			structMemberAssignment = t.TranslateToInternal("s." + forMember.PublicName())
@@ -97,15 +104,15 @@ func (t *pointerType) PrintVulkanizeContent(forMember *structMember, preamble io
 			// still need to check for empty slice and pass nil instead.
 			pre := fmt.Sprintf(sliceDirectTemplate,
 				forMember.InternalName(), forMember.resolvedType.InternalName(),
-				forMember.PublicName(),
-				forMember.InternalName(), forMember.PublicName(),
+				guard,
+				forMember.InternalName(), forMember.resolvedType.InternalName(), forMember.PublicName(),
 			)
 			fmt.Fprint(preamble, pre)
 			structMemberAssignment = "psl_" + forMember.InternalName()
 		} else {
 			pre := fmt.Sprintf(sliceTranslationTemplate,
 				forMember.InternalName(), forMember.resolvedType.InternalName(),
-				forMember.PublicName(),
+				guard,
 				forMember.InternalName(), t.resolvedPointsAtType.InternalName(), forMember.PublicName(),
 				forMember.PublicName(),
 				forMember.InternalName(), t.resolvedPointsAtType.TranslateToInternal("v"),
@@ -130,19 +137,19 @@ func (t *pointerType) PrintVulkanizeContent(forMember *structMember, preamble io
 
 const sliceDirectTemplate string = `
 var psl_%s %s
-if len(s.%s) > 0 {
-	psl_%s = &s.%s[0]
+if %s {
+	psl_%s = (%s)(unsafe.SliceData(s.%s))
 }
 `
 
 const sliceTranslationTemplate string = `
   var psl_%s %s
-  if len(s.%s) > 0 {
+  if %s {
 	sl_%s := make([]%s, len(s.%s))
 	for i, v := range s.%s {
 		sl_%s[i] = %s
 	}
-	psl_%s = &sl_%s[0]
+	psl_%s = unsafe.SliceData(sl_%s)
   }
 `
 
@@ -158,7 +165,7 @@ func (t *pointerType) PrintPublicToInternalTranslation(w io.Writer, publicValueN
 		fmt.Fprintf(w, "  sl_%s[i] = tmp\n", internalValueName)
 		fmt.Fprintf(w, "}\n")
 
-		fmt.Fprintf(w, "%s := &sl_%s[0]\n", internalValueName, internalValueName)
+		fmt.Fprintf(w, "%s := unsafe.SliceData(sl_%s)\n", internalValueName, internalValueName)
 
 		if t.lenSpec != "" {
 			// if lenspec is empty this is one of the few altlen elements
@@ -184,7 +191,7 @@ func (t *pointerType) PrintPublicToInternalTranslation(w io.Writer, publicValueN
 		}
 		fmt.Fprintln(w, "}")
 
-		fmt.Fprintf(w, "%s := &sl_%s[0]\n", internalValueName, internalValueName)
+		fmt.Fprintf(w, "%s := unsafe.SliceData(sl_%s)\n", internalValueName, internalValueName)
 
 		if t.lenSpec != "" {
 			// if lenspec is empty this is one of the few altlen elements
@@ -3,6 +3,7 @@ package def
 import (
 	"fmt"
 	"io"
+	"regexp"
 	"sort"
 	"strconv"
 
@@ -35,6 +36,7 @@ const (
 	CatArray
 
 	CatCommand
+	CatFuncPointer
 
 	CatMaximum
 )
@@ -74,6 +76,8 @@ func (c TypeCategory) ReadFns() (fnReadFromXML, fnReadFromJSON) {
 
 	case CatCommand:
 		return ReadCommandTypesFromXML, ReadCommandExceptionsFromJSON
+	case CatFuncPointer:
+		return ReadFuncPointerTypesFromXML, nil
 
 	default:
 		return nil, nil
@@ -107,11 +111,76 @@ type Aliaser interface {
 	IsAlias() bool
 }
 
+// DanglingAliaser is implemented by types and values that can report an alias that was not found in the registry
+type DanglingAliaser interface {
+	// DanglingAlias returns the name of the alias target if it was not found when resolving, or an empty string
+	DanglingAlias() string
+}
+
 type Resolver interface {
 	Resolve(TypeRegistry, ValueRegistry) *IncludeSet
 	IsIdenticalPublicAndInternal() bool
 }
 
+// Deprecator is implemented by types that can be flagged as deprecated in the generated doc comments
+type Deprecator interface {
+	SetDeprecated(note string)
+}
+
+// Renamer is implemented by types and values whose public name can be replaced, e.g. to resolve a collision with
+// another identifier
+type Renamer interface {
+	SetPublicName(name string)
+}
+
+// PrintOptions selects optional parts of the generated code. The zero value prints the default output.
+type PrintOptions struct {
+	// MustWrappers adds a Must<Command> function for each command whose only success code is VK_SUCCESS. The wrapper
+	// returns the command's other results and panics if the command returns an error.
+	MustWrappers bool
+	// HandleMethods adds a method to the handle type for each command whose first parameter is that handle, e.g.
+	// device.CreateBuffer(...) for CreateBuffer(device, ...). The free functions are still generated.
+	HandleMethods bool
+
+	// UntypedEnumValues and TypedAPIConstants select whether values are declared with their type, e.g.
+	// FORMAT_UNDEFINED Format = 0, or as untyped constants, e.g. MAX_EXTENSION_NAME_SIZE = 256. Untyped values can be
+	// used wherever a number of any type is expected, but lose the compile time check that a value belongs to the
+	// right enum. Values of handle and other types are always typed.
+	UntypedEnumValues bool // enum and bitmask values
+	TypedAPIConstants bool // the "API Constants" block, like VK_MAX_EXTENSION_NAME_SIZE and VK_WHOLE_SIZE
+}
+
+// isTyped is true if values of td are declared with their type
+func (o PrintOptions) isTyped(td TypeDefiner) bool {
+	switch td.Category() {
+	case CatEnum, CatBitmask:
+		return !o.UntypedEnumValues
+	case CatExternal:
+		return o.TypedAPIConstants
+	}
+	return true
+}
+
+// PrintConfigurer is implemented by types and values whose output depends on PrintOptions
+type PrintConfigurer interface {
+	SetPrintOptions(opts PrintOptions)
+}
+
+// SetPrintOptions sets opts on every type and value in the registries that implements PrintConfigurer. It must be
+// called before printing, after everything has been read into the registries.
+func SetPrintOptions(tr TypeRegistry, vr ValueRegistry, opts PrintOptions) {
+	for _, td := range tr {
+		if c, ok := td.(PrintConfigurer); ok {
+			c.SetPrintOptions(opts)
+		}
+	}
+	for _, vd := range vr {
+		if c, ok := vd.(PrintConfigurer); ok {
+			c.SetPrintOptions(opts)
+		}
+	}
+}
+
 type TypeDefiner interface {
 	Category() TypeCategory
 	Namer
@@ -137,6 +206,26 @@ type Printer interface {
 	TranslateToInternal(inputVar string) string
 }
 
+// Sorted returns the types in the registry sorted by registry name, for deterministic output
+func (tr TypeRegistry) Sorted() []TypeDefiner {
+	rval := make([]TypeDefiner, 0, len(tr))
+	for _, v := range tr {
+		rval = append(rval, v)
+	}
+	sort.Slice(rval, func(i, j int) bool { return rval[i].RegistryName() < rval[j].RegistryName() })
+	return rval
+}
+
+// Sorted returns the values in the registry sorted by value, then by registry name. See ByValue.
+func (vr ValueRegistry) Sorted() []ValueDefiner {
+	rval := make([]ValueDefiner, 0, len(vr))
+	for _, v := range vr {
+		rval = append(rval, v)
+	}
+	sort.Sort(ByValue(rval))
+	return rval
+}
+
 type ImportMap map[string]bool
 
 func (m ImportMap) SortedKeys() []string {
@@ -148,11 +237,55 @@ func (m ImportMap) SortedKeys() []string {
 	return rval
 }
 
+// ByName sorts types by public name, except that an alias, like a promoted extension's FooKHR, is placed directly after
+// the type it aliases
 type ByName []TypeDefiner
 
-func (a ByName) Len() int           { return len(a) }
-func (a ByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a ByName) Less(i, j int) bool { return a[i].PublicName() < a[j].PublicName() }
+func (a ByName) Len() int      { return len(a) }
+func (a ByName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByName) Less(i, j int) bool {
+	ci, di := canonicalType(a[i])
+	cj, dj := canonicalType(a[j])
+	if ci != cj {
+		if ci.PublicName() == cj.PublicName() {
+			return ci.RegistryName() < cj.RegistryName()
+		}
+		return ci.PublicName() < cj.PublicName()
+	}
+	if di != dj {
+		return di < dj
+	}
+	return a[i].RegistryName() < a[j].RegistryName()
+}
+
+// maxAliasDepth bounds the alias chains followed by canonicalType and canonicalValue, in case of a cycle
+const maxAliasDepth = 8
+
+// canonicalType follows a type's aliases to the type they end at, returning it with the number of aliases followed
+func canonicalType(td TypeDefiner) (TypeDefiner, int) {
+	depth := 0
+	for ; depth < maxAliasDepth; depth++ {
+		a, isAliaser := td.(interface{ resolvedAlias() TypeDefiner })
+		if !isAliaser || a.resolvedAlias() == nil {
+			break
+		}
+		td = a.resolvedAlias()
+	}
+	return td, depth
+}
+
+// canonicalValue follows a value's aliases to the value they end at, returning it with the number of aliases followed
+func canonicalValue(vd ValueDefiner) (ValueDefiner, int) {
+	depth := 0
+	for ; depth < maxAliasDepth; depth++ {
+		a, isAliaser := vd.(interface{ resolvedAlias() ValueDefiner })
+		if !isAliaser || a.resolvedAlias() == nil {
+			break
+		}
+		vd = a.resolvedAlias()
+	}
+	return vd, depth
+}
 
 type ValueDefiner interface {
 	RegistryName() string
@@ -171,18 +304,46 @@ type ValueDefiner interface {
 	IsCore() bool
 }
 
+// parseValueString returns the integer value of a decimal or hex value string, e.g. 0x7FFFFFFF for the _MAX_ENUM
+// sentinels, or of a bit value like 1 << 3. ok is false for anything else, like aliases, floats and strings.
+func parseValueString(s string) (n int64, ok bool) {
+	if m := rxBitValue.FindStringSubmatch(s); m != nil {
+		pos, err := strconv.Atoi(m[1])
+		return int64(uint64(1) << pos), err == nil && pos < 64
+	}
+	n, err := strconv.ParseInt(s, 0, 64)
+	return n, err == nil
+}
+
+// rxBitValue matches the value strings of bitmask values given by bit position, see bitmaskValue.ValueString
+var rxBitValue = regexp.MustCompile(`^(?:uint64\(1\)|1) << (\d+)$`)
+
+// ByValue sorts values numerically, with bit values by their bit position. An alias is placed directly after the value
+// it aliases, so the canonical name is always declared first and the alias refers back to it.
 type ByValue []ValueDefiner
 
-// TODO: also sort by bitmask values and aliases
 func (a ByValue) Len() int      { return len(a) }
 func (a ByValue) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a ByValue) Less(i, j int) bool {
-	iNum, err1 := strconv.Atoi(a[i].ValueString())
-	jNum, err2 := strconv.Atoi(a[j].ValueString())
-	if err1 == nil && err2 == nil {
+	ci, di := canonicalValue(a[i])
+	cj, dj := canonicalValue(a[j])
+	if ci == cj {
+		if di != dj {
+			return di < dj
+		}
+		return a[i].RegistryName() < a[j].RegistryName()
+	}
+
+	iNum, ok1 := parseValueString(ci.ValueString())
+	jNum, ok2 := parseValueString(cj.ValueString())
+	if ok1 && ok2 && iNum != jNum {
 		return iNum < jNum
 	}
-	return a[i].ValueString() < a[j].ValueString()
+	if ci.ValueString() == cj.ValueString() || (ok1 && ok2) {
+		// Ties are broken by name so that output order does not depend on map iteration
+		return ci.RegistryName() < cj.RegistryName()
+	}
+	return ci.ValueString() < cj.ValueString()
 }
 
 type ByValuePublicName []ValueDefiner // add for cleanup/issue-3
@@ -210,6 +371,11 @@ func WriteStringerCommands(w io.Writer, defs []TypeDefiner, cat TypeCategory, fi
 
 	for j, v := range defs {
 
+		if _, ok := v.(*enumType); ok {
+			// Enums and flag bits generate their own String() method
+			continue
+		}
+
 		if v.Category() == cat && len(v.AllValues()) > 0 {
 			types += v.PublicName() + ","
 			i++
@@ -227,4 +393,10 @@ func WriteStringerCommands(w io.Writer, defs []TypeDefiner, cat TypeCategory, fi
 			i = 0
 		}
 	}
+
+	// The last type(s) in the list may have been skipped, leaving a partial batch
+	if types != "" {
+		outFile := fmt.Sprintf("%s_string_%d.go", filenameBase, fileCount)
+		fmt.Fprintf(w, "//go:generate stringer -output=%s -type=%s\n", outFile, types[:len(types)-1])
+	}
 }
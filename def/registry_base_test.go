@@ -0,0 +1,75 @@
+package def
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const aliasOrderFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkFlags</name>;</type>
+	<type requires="VkAccessFlagBits" category="bitmask">typedef <type>VkFlags</type> <name>VkAccessFlags</name>;</type>
+	<type name="VkAccessFlagBits" category="enum"/>
+	<type name="VkFormat" category="enum"/>
+	<type category="struct" name="VkRenderingInfoKHR" alias="VkRenderingInfo"/>
+	<type category="struct" name="VkRenderingInfo"><member><type>uint32_t</type> <name>flags</name></member></type>
+	<type category="struct" name="VkRenderingInfoAttachment"><member><type>uint32_t</type> <name>index</name></member></type>
+</types>
+<enums name="VkFormat" type="enum">
+	<enum name="VK_FORMAT_A_KHR" alias="VK_FORMAT_Z"/>
+	<enum value="10" name="VK_FORMAT_C"/>
+	<enum value="5" name="VK_FORMAT_Z"/>
+	<enum value="3" name="VK_FORMAT_B"/>
+	<enum name="VK_FORMAT_A2_KHR" alias="VK_FORMAT_A_KHR"/>
+</enums>
+<enums name="VkAccessFlagBits" type="bitmask">
+	<enum bitpos="10" name="VK_ACCESS_TEN_BIT"/>
+	<enum name="VK_ACCESS_A_TWO_BIT_KHR" alias="VK_ACCESS_TWO_BIT"/>
+	<enum bitpos="2" name="VK_ACCESS_TWO_BIT"/>
+	<enum value="0x00000008" name="VK_ACCESS_THREE_BIT"/>
+	<enum value="0" name="VK_ACCESS_NONE"/>
+</enums>
+</registry>`
+
+// declarationOrder returns the names in the order of their first declaration in src
+func declarationOrder(src string, names ...string) []string {
+	sort.Slice(names, func(i, j int) bool {
+		return strings.Index(src, "\n"+names[i]+" ") < strings.Index(src, "\n"+names[j]+" ")
+	})
+	return names
+}
+
+func TestAliasOrder(t *testing.T) {
+	tr, vr := readTestRegistry(t, aliasOrderFixture)
+	src := resolveAndPrint(t, tr, vr, "VkFlags", "VkAccessFlags", "VkAccessFlagBits", "VkFormat",
+		"VkRenderingInfo", "VkRenderingInfoKHR", "VkRenderingInfoAttachment")
+
+	types := ByName{tr["VkRenderingInfoAttachment"], tr["VkRenderingInfoKHR"], tr["VkRenderingInfo"]}
+	sort.Sort(types)
+	var typeNames []string
+	for _, td := range types {
+		typeNames = append(typeNames, td.PublicName())
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want string
+	}{
+		// The alias follows its target even though RenderingInfoAttachment sorts before RenderingInfoKHR by name
+		{"types", typeNames, "RenderingInfo,RenderingInfoKHR,RenderingInfoAttachment"},
+		// Numeric, with each alias after the value it aliases, whatever the XML order
+		{"enum values", declarationOrder(src, "FORMAT_A_KHR", "FORMAT_A2_KHR", "FORMAT_B", "FORMAT_C", "FORMAT_Z"),
+			"FORMAT_B,FORMAT_Z,FORMAT_A_KHR,FORMAT_A2_KHR,FORMAT_C"},
+		// By bit position rather than by the text of 1 << n, with masks given by value in among them
+		{"bit values", declarationOrder(src, "ACCESS_TEN_BIT", "ACCESS_A_TWO_BIT_KHR", "ACCESS_TWO_BIT",
+			"ACCESS_THREE_BIT", "ACCESS_NONE"),
+			"ACCESS_NONE,ACCESS_TWO_BIT,ACCESS_A_TWO_BIT_KHR,ACCESS_THREE_BIT,ACCESS_TEN_BIT"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.got, ","); got != tt.want {
+			t.Errorf("%s: got %s, want %s\n%s", tt.name, got, tt.want, src)
+		}
+	}
+}
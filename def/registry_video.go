@@ -0,0 +1,55 @@
+package def
+
+import (
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/sirupsen/logrus"
+)
+
+// ReadVideoRegistryFromXML reads the enum, struct and union types from the Vulkan Video registry (video.xml), which
+// defines the StdVideo* types referenced by the video extensions. Definitions read here replace the placeholder
+// entries from the "external" section of exceptions.json. Structs with C bitfield members can't be laid out in Go,
+// so those keep their placeholder and are skipped with a warning.
+//
+// The returned IncludeSet holds the codec constants (array sizes and the like), which are untyped and so will not be
+// picked up by any Feature unless it is merged in explicitly.
+func ReadVideoRegistryFromXML(doc *xmlquery.Node, tr TypeRegistry, vr ValueRegistry, api string) *IncludeSet {
+	rval := NewIncludeSet()
+
+	ReadEnumTypesFromXML(doc, tr, vr, api)
+	ReadUnionTypesFromXML(doc, tr, vr, api)
+
+	videoTypes := make(TypeRegistry)
+	ReadStructTypesFromXML(doc, videoTypes, vr, api)
+	for k, v := range videoTypes {
+		if hasBitfieldMembers(xmlquery.FindOne(doc, "//types/type[@name='"+k+"']")) {
+			logrus.WithField("registry name", k).
+				Warn("video struct has bitfield members, keeping the placeholder type from exceptions.json")
+			continue
+		}
+		tr[k] = v
+	}
+
+	for _, node := range xmlquery.Find(doc, "//enums[@type='constants']/enum") {
+		valDef := NewUntypedEnumValueFromXML(node)
+		vr[valDef.RegistryName()] = valDef
+		rval.IncludeValues[valDef.RegistryName()] = true
+	}
+
+	return rval
+}
+
+// hasBitfieldMembers reports whether any member of the struct node declares a bit width, e.g.
+// <member><type>uint32_t</type> <name>flag</name> : 1</member>
+func hasBitfieldMembers(node *xmlquery.Node) bool {
+	if node == nil {
+		return false
+	}
+	for _, m := range xmlquery.Find(node, "member") {
+		if strings.Contains(m.InnerText(), ":") {
+			return true
+		}
+	}
+	return false
+}
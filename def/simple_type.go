@@ -1,5 +1,7 @@
 package def
 
+import "github.com/sirupsen/logrus"
+
 // simpleType is a base definition for any Vulkan type that maps an external
 // type.
 type simpleType struct {
@@ -21,6 +23,13 @@ func (t *simpleType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 
 	is := t.genericType.Resolve(tr, vr)
 	t.resolvedUnderlyingType = tr[t.underlyingTypeName]
+	if t.resolvedUnderlyingType == nil {
+		// C types are mapped to Go types by the "external" section of exceptions.json
+		logrus.WithField("registry name", t.registryName).
+			WithField("underlying type", t.underlyingTypeName).
+			Error("underlying C type has no Go mapping, add it to exceptions.json; using uintptr")
+		t.resolvedUnderlyingType = NewUnresolvedType(t.underlyingTypeName)
+	}
 	is.MergeWith(t.resolvedUnderlyingType.Resolve(tr, vr))
 
 	is.ResolvedTypes[t.registryName] = t
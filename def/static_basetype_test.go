@@ -0,0 +1,30 @@
+package def
+
+import "testing"
+
+// basetypeStubs declares the generated names that static_basetype.go uses
+const basetypeStubs = `
+type Bool32 uint32
+
+const (
+	FALSE = 0
+	TRUE  = 1
+)
+`
+
+func TestBool32Helpers(t *testing.T) {
+	out := runGenerated(t, readStatic(t, "static_basetype.go")+basetypeStubs, `	for _, b := range []Bool32{0, 1, 2, 0xFFFFFFFF} {
+		fmt.Println(b, b.ToGo())
+	}
+	fmt.Println(FromBool(true), FromBool(false))`, "fmt")
+
+	// Only 0 is false, as in C
+	want := "0 false\n" +
+		"1 true\n" +
+		"2 true\n" +
+		"4294967295 true\n" +
+		"1 0\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
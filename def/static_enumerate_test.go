@@ -0,0 +1,81 @@
+package def
+
+import "testing"
+
+// enumerateStubs declares the generated names that static_enumerate.go uses
+const enumerateStubs = `
+type Result int32
+
+func (r Result) Error() string { return fmt.Sprintf("Result(%d)", int32(r)) }
+
+const (
+	INCOMPLETE               Result = 5
+	ERROR_OUT_OF_HOST_MEMORY Result = -1
+)
+
+var SUCCESS error = nil
+
+// fakeEnumerate simulates an enumeration command. available[i] is the number of elements that exist at the i-th call,
+// and the call numbered failAt returns an error.
+func fakeEnumerate(available []uint32, failAt int) func(count *uint32, data *int) Result {
+	call := 0
+	return func(count *uint32, data *int) Result {
+		defer func() { call++ }()
+		if call == failAt {
+			return ERROR_OUT_OF_HOST_MEMORY
+		}
+		n := available[len(available)-1]
+		if call < len(available) {
+			n = available[call]
+		}
+		if data == nil {
+			*count = n
+			return Result(0)
+		}
+		out := unsafe.Slice(data, *count)
+		written := *count
+		if n < written {
+			written = n
+		}
+		for i := range out[:written] {
+			out[i] = i + 1
+		}
+		*count = written
+		if n > written {
+			return INCOMPLETE
+		}
+		return Result(0)
+	}
+}
+`
+
+func TestEnumerate(t *testing.T) {
+	decls := readStatic(t, "static_enumerate.go") + enumerateStubs
+
+	out := runGenerated(t, decls, `	for _, c := range []struct {
+		available []uint32
+		failAt    int
+	}{
+		{[]uint32{3}, -1},
+		{[]uint32{0}, -1},
+		// Grows between the count and data calls, twice
+		{[]uint32{2, 3, 3, 4, 4}, -1},
+		// Shrinks, only the written elements are returned
+		{[]uint32{3, 2}, -1},
+		{[]uint32{3}, 0},
+		{[]uint32{3}, 1},
+	} {
+		data, err := Enumerate(fakeEnumerate(c.available, c.failAt))
+		fmt.Println(data, err)
+	}`, "fmt", "unsafe")
+
+	want := "[1 2 3] <nil>\n" +
+		"[] <nil>\n" +
+		"[1 2 3 4] <nil>\n" +
+		"[1 2] <nil>\n" +
+		"[] Result(-1)\n" +
+		"[] Result(-1)\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,157 @@
+package def
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// primitiveLayouts gives the C size (and alignment) of the Go types that external C types are mapped to in
+// exceptions.json, for a 64-bit target
+var primitiveLayouts = map[string]int{
+	"int8": 1, "uint8": 1, "byte": 1,
+	"int16": 2, "uint16": 2,
+	"int32": 4, "uint32": 4, "float32": 4,
+	"int64": 8, "uint64": 8, "float64": 8,
+	"uintptr": 8, "unsafe.Pointer": 8,
+}
+
+// resolvedAlias gives cLayout access to the aliased type of any type embedding genericType
+func (t *genericType) resolvedAlias() TypeDefiner { return t.resolvedAliasType }
+
+// cLayout returns the size and alignment that a C compiler would give td on a 64-bit target, following the usual
+// rule that each member is aligned to its own alignment and a struct is padded to a multiple of its largest. ok is
+// false if the layout can't be known, e.g. for platform types mapped to uint32 or video types that were not read.
+func cLayout(td TypeDefiner, vr ValueRegistry) (size, align int, ok bool) {
+	if a, isAliaser := td.(interface{ resolvedAlias() TypeDefiner }); isAliaser && a.resolvedAlias() != nil {
+		return cLayout(a.resolvedAlias(), vr)
+	}
+
+	switch t := td.(type) {
+	case *unresolvedType:
+		return 0, 0, false
+
+	case *pointerType, *handleType, *funcPointerType:
+		// Non-dispatchable handles are always 64 bits, dispatchable handles are pointers
+		return 8, 8, true
+
+	case *enumType:
+		if t.bitWidth == 64 {
+			return 8, 8, true
+		}
+		return 4, 4, true
+
+	case *bitmaskType:
+		if t.underlyingType == nil {
+			return 0, 0, false
+		}
+		return cLayout(t.underlyingType, vr)
+
+	case *baseType:
+		if t.resolvedUnderlyingType == nil {
+			return 0, 0, false
+		}
+		return cLayout(t.resolvedUnderlyingType, vr)
+
+	case *externalType:
+		s, found := primitiveLayouts[t.InternalName()]
+		return s, s, found
+
+	case *arrayType:
+		n, err := strconv.Atoi(t.lenSpec)
+		if err != nil {
+			lenVal := vr[t.lenSpec]
+			if lenVal == nil {
+				return 0, 0, false
+			}
+			if n, err = strconv.Atoi(lenVal.ValueString()); err != nil {
+				return 0, 0, false
+			}
+		}
+		s, a, ok := cLayout(t.resolvedPointsAtType, vr)
+		return n * s, a, ok
+
+	case *unionType:
+		for _, m := range t.members {
+			s, a, ok := cLayout(m.resolvedType, vr)
+			if !ok {
+				return 0, 0, false
+			}
+			size, align = max(size, s), max(align, a)
+		}
+		return roundUp(size, align), align, align > 0
+
+	case *structType:
+		_, size, align, ok = structOffsets(t, vr)
+		return size, align, ok
+	}
+
+	return 0, 0, false
+}
+
+// structOffsets returns the C offset of each member of t along with its size and alignment. A bitfield member is
+// given the offset of its storage unit, and only the first member of a unit takes up space.
+func structOffsets(t *structType, vr ValueRegistry) (offsets []int, size, align int, ok bool) {
+	for _, m := range t.members {
+		if m.bitWidth > 0 && m.bitfieldUnit != nil && m.bitfieldUnit != m {
+			offsets = append(offsets, offsets[len(offsets)-1])
+			continue
+		}
+		s, a, ok := cLayout(m.resolvedType, vr)
+		if !ok {
+			return nil, 0, 0, false
+		}
+		size = roundUp(size, a)
+		offsets = append(offsets, size)
+		size += s
+		align = max(align, a)
+	}
+	return offsets, roundUp(size, align), align, align > 0
+}
+
+func roundUp(n, multiple int) int {
+	if multiple == 0 {
+		return n
+	}
+	return (n + multiple - 1) / multiple * multiple
+}
+
+// WriteStructSizeTest writes a test that compares unsafe.Sizeof and unsafe.Offsetof for the internal declaration of
+// each struct and union in types against the layout computed from the registry's member types, including alignment
+// padding and packed bitfields. The generated declarations rely on Go laying out fields the same way as C, which holds
+// for the Go types used here on 64-bit targets; the test catches any member mapped to a Go type of the wrong size.
+// Types whose C layout can't be computed are left out.
+func WriteStructSizeTest(w io.Writer, types []TypeDefiner, vr ValueRegistry) {
+	fmt.Fprint(w, "func TestStructSizes(t *testing.T) {\n")
+	fmt.Fprint(w, "cases := []struct {\nname string\ngot, want uintptr\n}{\n")
+	for _, td := range types {
+		if td.Category() != CatStruct && td.Category() != CatUnion || td.PublicName() == "!ignore" {
+			continue
+		}
+		size, _, ok := cLayout(td, vr)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "{\"size of %s\", unsafe.Sizeof(%s{}), %d},\n", td.RegistryName(), td.InternalName(), size)
+
+		st, isStruct := td.(*structType)
+		if !isStruct {
+			continue
+		}
+		offsets, _, _, _ := structOffsets(st, vr)
+		for i, m := range st.members {
+			if m.bitWidth > 0 && m.bitfieldUnit != m {
+				continue
+			}
+			field := m.InternalName()
+			if st.IsIdenticalPublicAndInternal() {
+				field = m.PublicName()
+			}
+			fmt.Fprintf(w, "{\"offset of %s.%s\", unsafe.Offsetof(%s{}.%s), %d},\n", td.RegistryName(), m.registryName, td.InternalName(), field, offsets[i])
+		}
+	}
+	fmt.Fprint(w, "}\n\n")
+	fmt.Fprint(w, "for _, c := range cases {\nif c.got != c.want {\n")
+	fmt.Fprintf(w, "t.Errorf(\"%%s is %%d bytes in Go and %%d bytes in C\", c.name, c.got, c.want)\n")
+	fmt.Fprint(w, "}\n}\n}\n")
+}
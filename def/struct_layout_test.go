@@ -0,0 +1,106 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+const layoutFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint64_t</type> <name>VkDeviceAddress</name>;</type>
+	<type category="bitmask">typedef <type>VkFlags</type> <name>VkGeometryInstanceFlagsKHR</name>;</type>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkFlags</name>;</type>
+	<type category="struct" name="VkExamplePadded">
+		<member><type>uint32_t</type> <name>count</name></member>
+		<member><type>uint64_t</type> <name>size</name></member>
+		<member><type>uint8_t</type> <name>tag</name></member>
+	</type>
+	<type category="struct" name="VkExampleInstance">
+		<member><type>uint32_t</type> <name>instanceCustomIndex</name>:24</member>
+		<member><type>uint32_t</type> <name>mask</name>:8</member>
+		<member><type>uint32_t</type> <name>instanceShaderBindingTableRecordOffset</name>:24</member>
+		<member><type>VkGeometryInstanceFlagsKHR</type> <name>flags</name>:8</member>
+		<member><type>VkDeviceAddress</type> <name>accelerationStructureReference</name></member>
+	</type>
+</types>
+</registry>`
+
+func TestStructLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantSize    int
+		wantOffsets []int
+	}{
+		// size is aligned to 8 after count, and the struct is padded to a multiple of 8 after tag
+		{"VkExamplePadded", 24, []int{0, 8, 16}},
+		// Each pair of bitfields shares one uint32
+		{"VkExampleInstance", 16, []int{0, 0, 4, 4, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, layoutFixture)
+			resolveAndPrint(t, tr, vr, tt.name)
+
+			offsets, size, _, ok := structOffsets(tr[tt.name].(*structType), vr)
+			if !ok {
+				t.Fatal("layout could not be computed")
+			}
+			if size != tt.wantSize {
+				t.Errorf("size: got %d, want %d", size, tt.wantSize)
+			}
+			for i := range tt.wantOffsets {
+				if i >= len(offsets) || offsets[i] != tt.wantOffsets[i] {
+					t.Errorf("offsets: got %v, want %v", offsets, tt.wantOffsets)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestStructSizeTestOutput(t *testing.T) {
+	tr, vr := readTestRegistry(t, layoutFixture)
+	resolveAndPrint(t, tr, vr, "VkExamplePadded", "VkExampleInstance")
+
+	sb := &strings.Builder{}
+	WriteStructSizeTest(sb, []TypeDefiner{tr["VkExamplePadded"], tr["VkExampleInstance"]}, vr)
+	src := sb.String()
+
+	for _, want := range []string{
+		`{"size of VkExamplePadded", unsafe.Sizeof(_vkExamplePadded{}), 24},`,
+		`{"offset of VkExamplePadded.tag", unsafe.Offsetof(_vkExamplePadded{}.Tag), 16},`,
+		`{"size of VkExampleInstance", unsafe.Sizeof(_vkExampleInstance{}), 16},`,
+		`{"offset of VkExampleInstance.instanceShaderBindingTableRecordOffset", unsafe.Offsetof(_vkExampleInstance{}.instanceShaderBindingTableRecordOffset), 4},`,
+		`{"offset of VkExampleInstance.accelerationStructureReference", unsafe.Offsetof(_vkExampleInstance{}.accelerationStructureReference), 8},`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("want %q in\n%s", want, src)
+		}
+	}
+	if strings.Contains(src, "VkExampleInstance.mask") {
+		t.Errorf("want no offset case for a bitfield sharing a unit in\n%s", src)
+	}
+}
+
+func TestBitfieldRoundTrip(t *testing.T) {
+	tr, vr := readTestRegistry(t, layoutFixture)
+	src := resolveAndPrint(t, tr, vr, "VkFlags", "VkDeviceAddress", "VkGeometryInstanceFlagsKHR", "VkExampleInstance")
+
+	out := runGenerated(t, src, `
+	in := ExampleInstance{
+		InstanceCustomIndex:                    0xabcdef,
+		Mask:                                   0x12,
+		InstanceShaderBindingTableRecordOffset: 0x345678,
+		Flags:                                  0x9a,
+		AccelerationStructureReference:         0x1122334455667788,
+	}
+	c := in.Vulkanize()
+	fmt.Println(unsafe.Sizeof(*c), *(*[2]uint32)(unsafe.Pointer(c)))
+	fmt.Println(*c.Goify() == in)`, "fmt", "unsafe")
+
+	want := "16 [313249263 2587121272]\ntrue\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
@@ -18,6 +20,8 @@ type structType struct {
 
 	forceIncludeMemberName string
 	forceIncludeComment    string
+
+	structExtends []string // the structs this one can be chained onto through their pNext
 }
 
 type structMember struct {
@@ -28,6 +32,7 @@ type structMember struct {
 	lenSpecString       string
 	lenSpecs            []string
 	altLenSpec          string
+	lenFormula          string
 	isLenForOtherMember []*structMember
 
 	fixedLengthArray bool
@@ -41,6 +46,17 @@ type structMember struct {
 	forceInclude       bool
 	comment            string
 	noAutoValidityFlag bool
+
+	// optional is parsed from the optional attribute, one entry per pointer level (e.g. "true,false"). The first
+	// entry says whether the member itself may be nil or zero.
+	optional []bool
+
+	// bitWidth is the width of a C bitfield member, e.g. 24 for instanceCustomIndex:24, and 0 for other members.
+	// Adjacent bitfields share a storage unit of their type's size, as in C; the internal struct declares one field per
+	// unit, named after bitfieldUnit (the first member in it), and bitOffset is the member's position within the unit.
+	bitWidth, bitOffset int
+	bitfieldUnit        *structMember
+	bitfieldGroup       []*structMember // the members sharing the unit, only set on the unit member
 }
 
 func (t *structType) Category() TypeCategory { return CatStruct }
@@ -50,7 +66,9 @@ func (t *structType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 		return NewIncludeSet()
 	}
 
-	t.isResolved = true // Moved here from end of function as part of issue #4 fix
+	// Moved here from end of function as part of issue #4 fix. Setting this before resolving members is what allows
+	// self-referential and mutually referential struct pointers to terminate.
+	t.isResolved = true
 
 	if t.publicName == "!ignore" {
 		t.isResolved = true
@@ -69,9 +87,12 @@ func (t *structType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 				Error("alias not found in registry while resolving type")
 			return NewIncludeSet()
 		} else {
-			t.resolvedAliasType.Resolve(tr, vr)
+			// The alias is emitted as a Go type alias, so it must be part of the resolved set along with everything
+			// the aliased type requires
 			rval := NewIncludeSet()
+			rval.MergeWith(t.resolvedAliasType.Resolve(tr, vr))
 			rval.IncludeTypes[t.aliasTypeName] = true
+			rval.ResolvedTypes[t.registryName] = t
 			return rval
 		}
 	}
@@ -108,12 +129,48 @@ func (t *structType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 		}
 	}
 
+	t.groupBitfields()
+
 	rb.ResolvedTypes[t.registryName] = t
 
 	return rb
 }
 
+// groupBitfields assigns each bitfield member to a storage unit. A member starts a new unit unless the previous member
+// is a bitfield of the same size with enough bits left, like instanceCustomIndex:24 and mask:8 sharing a uint32.
+func (t *structType) groupBitfields() {
+	var unit *structMember
+	var unitBits, usedBits int
+	for _, m := range t.members {
+		if m.bitWidth == 0 || m.resolvedType == nil {
+			unit = nil
+			continue
+		}
+		size, _, _ := cLayout(m.resolvedType, nil)
+		if unit == nil || size*8 != unitBits || usedBits+m.bitWidth > unitBits {
+			unit, unitBits, usedBits = m, size*8, 0
+		}
+		m.bitfieldUnit, m.bitOffset = unit, usedBits
+		unit.bitfieldGroup = append(unit.bitfieldGroup, m)
+		usedBits += m.bitWidth
+	}
+}
+
+// bitfieldStorageTypes gives the Go type of a bitfield storage unit, by its size in bytes
+var bitfieldStorageTypes = map[int]string{1: "uint8", 2: "uint16", 4: "uint32", 8: "uint64"}
+
+// storageType returns the Go type of the member's bitfield unit
+func (m *structMember) storageType() string {
+	size, _, _ := cLayout(m.bitfieldUnit.resolvedType, nil)
+	return bitfieldStorageTypes[size]
+}
+
+func (m *structMember) bitMask() string { return fmt.Sprintf("%#x", uint64(1)<<m.bitWidth-1) }
+
 func (t *structType) IsIdenticalPublicAndInternal() bool {
+	if t.IsAlias() {
+		return t.resolvedAliasType.IsIdenticalPublicAndInternal()
+	}
 	for _, m := range t.members {
 		// part of fix for issue #4
 		if asPointerType, isPointer := m.resolvedType.(*pointerType); isPointer && asPointerType.resolvedPointsAtType == t {
@@ -157,10 +214,192 @@ func (t *structType) PrintPublicDeclaration(w io.Writer) {
 		}
 
 		fmt.Fprintf(w, "}\n\n")
+
+		t.printChainNodeMethods(w)
+	}
+}
+
+// PrintFileInitContent registers structs with a fixed sType in the structureTypeOf lookup (see static_common.go)
+func (t *structType) PrintFileInitContent(w io.Writer) {
+	if t.IsAlias() {
+		return
+	}
+	for _, m := range t.members {
+		if m.registryName == "sType" && m.resolvedValue != nil {
+			fmt.Fprintf(w, "  structureTypeOf[reflect.TypeOf(%s{})] = %s\n", t.PublicName(), m.resolvedValue.PublicName())
+			return
+		}
+	}
+}
+
+// chainMembers returns the sType and pNext members of the struct, if the struct has both and sType has a fixed value.
+// These structs can be linked into a pNext chain.
+func (t *structType) chainMembers() (sType, pNext *structMember) {
+	for _, m := range t.members {
+		switch m.registryName {
+		case "sType":
+			if m.resolvedValue != nil {
+				sType = m
+			}
+		case "pNext":
+			pNext = m
+		}
+	}
+	if sType == nil || pNext == nil {
+		return nil, nil
+	}
+	return sType, pNext
+}
+
+// printChainNodeMethods implements the ChainNode interface (see static_common.go) for structs that can be linked into a
+// pNext chain.
+func (t *structType) printChainNodeMethods(w io.Writer) {
+	sType, pNext := t.chainMembers()
+	if sType == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "// StructureType returns the sType value that Vulkanize sets for %s\n", t.PublicName())
+	fmt.Fprintf(w, "func (s *%s) StructureType() StructureType { return %s }\n\n", t.PublicName(), sType.resolvedValue.PublicName())
+
+	fmt.Fprintf(w, "// NextPtr returns the address of the PNext member, allowing the chain to be walked and extended\n")
+	fmt.Fprintf(w, "func (s *%s) NextPtr() *unsafe.Pointer { return &s.%s }\n\n", t.PublicName(), pNext.PublicName())
+
+	fmt.Fprintf(w, "func (s *%s) vulkanizeChainNode() unsafe.Pointer { return unsafe.Pointer(s.Vulkanize()) }\n\n", t.PublicName())
+}
+
+// isDeviceFeaturesStruct reports whether the struct enables physical device features when chained onto
+// DeviceCreateInfo, like PhysicalDeviceVulkan13Features
+func (t *structType) isDeviceFeaturesStruct() bool {
+	if t.IsAlias() || !strings.Contains(t.registryName, "Features") {
+		return false
+	}
+	if sType, _ := t.chainMembers(); sType == nil {
+		return false
+	}
+	for _, e := range t.structExtends {
+		if e == "VkDeviceCreateInfo" || e == "VkPhysicalDeviceFeatures2" {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	rxCoreVersion    = regexp.MustCompile(`^VK_VERSION_(\d+)_(\d+)$`)
+	rxVulkanFeatures = regexp.MustCompile(`^VkPhysicalDeviceVulkan\d+Features$`)
+)
+
+// WriteFeatureChain writes FeatureChain, which links the physical device features structs in types behind
+// PhysicalDeviceFeatures2, and NewFeatureChain, which fills in the members that a device with the given API version and
+// extensions can use. originOf returns the feature or extension that introduced a struct (see feat.Feature.OriginOf);
+// structs without an origin are always included. A struct introduced by core version 1.N is left out when
+// PhysicalDeviceVulkan1NFeatures is in the chain, since VkDeviceCreateInfo must not have both
+// (VUID-VkDeviceCreateInfo-pNext-02830).
+func WriteFeatureChain(w io.Writer, types []TypeDefiner, originOf func(registryName string) string) {
+	var head *structType
+	var covering, others []TypeDefiner
+	promotedBy := make(map[string][]string) // extensions whose alias of a features struct was promoted to core
+	for _, td := range types {
+		st, ok := td.(*structType)
+		if !ok {
+			continue
+		}
+		if st.IsAlias() {
+			if base, ok := st.resolvedAliasType.(*structType); ok && base.isDeviceFeaturesStruct() {
+				promotedBy[base.registryName] = append(promotedBy[base.registryName], originOf(st.registryName))
+			}
+			continue
+		}
+		switch {
+		case !st.isDeviceFeaturesStruct():
+		case st.registryName == "VkPhysicalDeviceFeatures2":
+			head = st
+		case rxVulkanFeatures.MatchString(st.registryName):
+			covering = append(covering, st)
+		default:
+			others = append(others, st)
+		}
+	}
+	if head == nil {
+		logrus.Warn("PhysicalDeviceFeatures2 is not generated, skipping the feature chain")
+		return
+	}
+	sort.Sort(ByName(covering))
+	sort.Sort(ByName(others))
+	members := append(covering, others...)
+
+	inChain := make(map[string]TypeDefiner)
+	for _, td := range members {
+		inChain[td.RegistryName()] = td
+	}
+	fieldName := func(td TypeDefiner) string { return strings.TrimPrefix(td.PublicName(), "PhysicalDevice") }
+
+	fmt.Fprint(w, "// FeatureChain is a pNext chain of physical device features structs, created by NewFeatureChain. Set the features to\n")
+	fmt.Fprint(w, "// enable on its members and link it onto DeviceCreateInfo with AppendNext, leaving DeviceCreateInfo.PEnabledFeatures\n")
+	fmt.Fprint(w, "// nil. The members are linked behind PhysicalDeviceFeatures2 in order, and sTyped, when the chain is Vulkanized; nil\n")
+	fmt.Fprint(w, "// members are left out.\n")
+	fmt.Fprint(w, "type FeatureChain struct {\n")
+	fmt.Fprintf(w, "%s %s\n", head.PublicName(), head.PublicName())
+	for _, td := range members {
+		fmt.Fprintf(w, "%s *%s\n", fieldName(td), td.PublicName())
+	}
+	fmt.Fprint(w, "}\n\n")
+
+	fmt.Fprint(w, "// NewFeatureChain returns a FeatureChain with an empty member for each features struct available to a device with\n")
+	fmt.Fprint(w, "// apiVersion and enabledExtensions (registry names, e.g. \"VK_KHR_ray_query\"). Structs that were promoted into\n")
+	fmt.Fprint(w, "// PhysicalDeviceVulkan11Features and its successors are left nil whenever those are members.\n")
+	fmt.Fprint(w, "func NewFeatureChain(apiVersion uint32, enabledExtensions ...string) *FeatureChain {\n")
+	fmt.Fprint(w, "enabled := make(map[string]bool)\nfor _, e := range enabledExtensions {\nenabled[e] = true\n}\n\n")
+	fmt.Fprint(w, "c := &FeatureChain{}\n")
+	for _, td := range members {
+		var terms []string
+		coveredBy := ""
+		if origin := originOf(td.RegistryName()); origin == "" {
+			terms = append(terms, "true")
+		} else if m := rxCoreVersion.FindStringSubmatch(origin); m != nil {
+			terms = append(terms, fmt.Sprintf("apiVersion >= makeApiVersion(0, %s, %s, 0)", m[1], m[2]))
+			if c := fmt.Sprintf("VkPhysicalDeviceVulkan%s%sFeatures", m[1], m[2]); c != td.RegistryName() && inChain[c] != nil {
+				coveredBy = fieldName(inChain[c])
+			}
+		} else {
+			terms = append(terms, fmt.Sprintf("enabled[%q]", origin))
+		}
+		for _, ext := range promotedBy[td.RegistryName()] {
+			if ext != "" && rxCoreVersion.FindStringSubmatch(ext) == nil {
+				terms = append(terms, fmt.Sprintf("enabled[%q]", ext))
+			}
+		}
+
+		cond := strings.Join(terms, " || ")
+		if coveredBy != "" {
+			if len(terms) > 1 {
+				cond = "(" + cond + ")"
+			}
+			cond += fmt.Sprintf(" && c.%s == nil", coveredBy)
+		}
+		fmt.Fprintf(w, "if %s {\nc.%s = &%s{}\n}\n", cond, fieldName(td), td.PublicName())
+	}
+	fmt.Fprint(w, "return c\n}\n\n")
+
+	fmt.Fprintf(w, "// StructureType returns the sType of %s, the head of the chain\n", head.PublicName())
+	fmt.Fprintf(w, "func (c *FeatureChain) StructureType() StructureType { return c.%s.StructureType() }\n\n", head.PublicName())
+	fmt.Fprintf(w, "// NextPtr returns the address of %s.PNext, so other structs can be appended to the chain\n", head.PublicName())
+	fmt.Fprintf(w, "func (c *FeatureChain) NextPtr() *unsafe.Pointer { return c.%s.NextPtr() }\n\n", head.PublicName())
+	fmt.Fprint(w, "func (c *FeatureChain) vulkanizeChainNode() unsafe.Pointer {\n")
+	fmt.Fprintf(w, "head := c.%s\n", head.PublicName())
+	for _, td := range members {
+		fmt.Fprintf(w, "if c.%s != nil {\nAppendNext(&head, c.%s)\n}\n", fieldName(td), fieldName(td))
 	}
+	fmt.Fprint(w, "return unsafe.Pointer(head.Vulkanize())\n}\n")
 }
 
 func (t *structType) PrintInternalDeclaration(w io.Writer) {
+	if t.IsAlias() {
+		// Goify and Vulkanize are inherited from the aliased type
+		fmt.Fprintf(w, "type %s = %s\n\n", t.InternalName(), t.resolvedAliasType.InternalName())
+		return
+	}
 
 	var preamble, structDecl, epilogue strings.Builder
 
@@ -260,15 +499,37 @@ func (m *structMember) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	m.resolvedType = previousTarget
 
 	if m.fixedLengthArray {
-		m.resolvedType = &arrayType{
-			resolvedPointsAtType: tr[m.typeRegistryName],
-			lenSpec:              m.lenSpecs[0],
+		// Nest from the innermost dimension out, so [3][4]float is an array of 3 [4]float32
+		var elemType TypeDefiner = tr[m.typeRegistryName]
+		for i := len(m.lenSpecs) - 1; i >= 0; i-- {
+			elemType = &arrayType{
+				resolvedPointsAtType: elemType,
+				lenSpec:              m.lenSpecs[i],
+			}
 		}
+		m.resolvedType = elemType
 	}
 
 	rval := NewIncludeSet()
 	rval.IncludeTypes[m.typeRegistryName] = true
 
+	if m.fixedLengthArray {
+		// Array sizes given as a constant, like VK_MAX_PHYSICAL_DEVICE_NAME_SIZE, must be declared in the output
+		for _, lenSpec := range m.lenSpecs {
+			if _, err := strconv.Atoi(lenSpec); err == nil {
+				continue
+			}
+			if lenVal := vr[lenSpec]; lenVal != nil {
+				rval.MergeWith(lenVal.Resolve(tr, vr))
+				rval.IncludeValues[lenSpec] = true
+			} else {
+				logrus.WithField("member", m.registryName).
+					WithField("array size", lenSpec).
+					Warn("fixed array size constant is not in the registry")
+			}
+		}
+	}
+
 	if m.resolvedType != nil {
 		rval.MergeWith(m.resolvedType.Resolve(tr, vr))
 	}
@@ -290,9 +551,12 @@ func (m *structMember) IsIdenticalPublicAndInternal() bool {
 	if m.resolvedType == nil {
 		return false
 	}
+	// pointerDepth must be checked before recursing into the member type. Structs can point at themselves, or at each
+	// other (VkBaseInStructure, VkBaseOutStructure), and the recursion would never terminate.
 	return m.resolvedValue == nil &&
-		m.resolvedType.IsIdenticalPublicAndInternal() &&
+		m.bitWidth == 0 &&
 		m.pointerDepth == 0 &&
+		m.resolvedType.IsIdenticalPublicAndInternal() &&
 		m.resolvedType.Category() != CatStruct &&
 		m.resolvedType.Category() != CatUnion
 }
@@ -307,6 +571,14 @@ func (m *structMember) PrintPublicDeclaration(w io.Writer) {
 	if m.comment != "" {
 		fmt.Fprintln(w, "// ", m.comment)
 	}
+	if m.lenFormula != "" {
+		// The length is a formula over other members, so the member stays a pointer and the caller sizes the array
+		if m.altLenSpec != "" {
+			fmt.Fprintf(w, "// Length: %s\n", m.altLenSpec)
+		} else {
+			fmt.Fprintf(w, "// Length: %s\n", m.lenFormula)
+		}
+	}
 
 	if m.forceInclude {
 		fmt.Fprintf(w, "%s %s // Forced include via exceptions.json\n", m.PublicName(), m.resolvedType.PublicName())
@@ -315,16 +587,42 @@ func (m *structMember) PrintPublicDeclaration(w io.Writer) {
 	} else if m.isLenForOtherMember != nil {
 		fmt.Fprintf(w, "// %s\n", m.InternalName())
 	} else {
+		if m.isRequiredPointer() {
+			fmt.Fprintln(w, "// Must not be nil")
+		}
 		fmt.Fprintf(w, "%s %s\n", m.PublicName(), m.resolvedType.PublicName())
 	}
 }
 
+// IsOptional reports whether the member may be nil (or zero, for non-pointer members), per the optional attribute
+func (m *structMember) IsOptional() bool { return len(m.optional) > 0 && m.optional[0] }
+
+// isRequiredPointer is true for a member declared as a single pointer in the public struct that the spec does not
+// allow to be nil. Members with noautovalidity are left out, since whether they may be nil depends on other members.
+func (m *structMember) isRequiredPointer() bool {
+	return m.pointerDepth > 0 &&
+		!m.IsOptional() &&
+		!m.noAutoValidityFlag &&
+		m.resolvedType.Category() == CatPointer &&
+		strings.HasPrefix(m.resolvedType.PublicName(), "*")
+}
+
 func (m *structMember) PrintInternalDeclaration(w io.Writer) {
 	// Skip members with unresolved types (e.g., external video codec types)
 	if m.resolvedType == nil {
 		fmt.Fprintf(w, "%s uintptr // Unresolved external type: %s\n", m.internalName, m.typeRegistryName)
 		return
 	}
+	if m.bitWidth > 0 {
+		if m.bitfieldUnit == m {
+			var fields []string
+			for _, n := range m.bitfieldGroup {
+				fields = append(fields, fmt.Sprintf("%s:%d", n.registryName, n.bitWidth))
+			}
+			fmt.Fprintf(w, "%s %s // bitfields %s\n", m.InternalName(), m.storageType(), strings.Join(fields, ", "))
+		}
+		return
+	}
 	fmt.Fprintf(w, "%s %s\n", m.InternalName(), m.resolvedType.InternalName())
 }
 
@@ -359,6 +657,15 @@ func (m *structMember) PrintVulanizeContent(preamble, structDecl, epilogue io.Wr
 	}
 
 	switch true {
+	case m.bitWidth > 0: // Bitfields are packed into their unit by its first member
+		if m.bitfieldUnit == m {
+			var parts []string
+			for _, n := range m.bitfieldGroup {
+				parts = append(parts, fmt.Sprintf("(%s(s.%s)&%s)<<%d", m.storageType(), n.PublicName(), n.bitMask(), n.bitOffset))
+			}
+			fmt.Fprintf(structDecl, "  %s : %s,/*bitfield*/\n", m.InternalName(), strings.Join(parts, " | "))
+		}
+
 	case m.resolvedValue != nil: // Edge case 1
 		fmt.Fprintf(structDecl, "  %s : %s,/*c1*/\n", m.InternalName(), m.resolvedValue.PublicName())
 
@@ -413,6 +720,9 @@ func (m *structMember) PrintGoifyContent(preamble, structDecl, epilogue io.Write
 	}
 
 	switch true {
+	case m.bitWidth > 0:
+		fmt.Fprintf(structDecl, "  %s : %s(s.%s>>%d&%s),/*bitfield*/\n", m.PublicName(), m.resolvedType.PublicName(), m.bitfieldUnit.InternalName(), m.bitOffset, m.bitMask())
+
 	case m.resolvedValue != nil: // Edge case 1 never happens in returned strucs
 
 	case m.resolvedType.Category() == CatUnion:
@@ -472,7 +782,12 @@ func newStructTypeFromXML(node *xmlquery.Node, api string) *structType {
 	rval := structType{}
 
 	rval.registryName = node.SelectAttr("name")
+	rval.aliasTypeName = node.SelectAttr("alias")
 	rval.isReturnedOnly = node.SelectAttr("returnedonly") == "true"
+	rval.comment = node.SelectAttr("comment")
+	if ext := node.SelectAttr("structextends"); ext != "" {
+		rval.structExtends = strings.Split(ext, ",")
+	}
 
 	queryString := fmt.Sprintf("member[(contains(@api,'%s') and not(@api='vulkansc')) or not(@api)]", api)
 	for _, mNode := range xmlquery.Find(node, queryString) {
@@ -482,9 +797,34 @@ func newStructTypeFromXML(node *xmlquery.Node, api string) *structType {
 	return &rval
 }
 
+// splitLenSpec splits a len attribute into one entry per pointer level. A length given as a LaTeX formula, like
+// latexmath:[\lceil{\mathit{rasterizationSamples} \over 32}\rceil] for VkPipelineMultisampleStateCreateInfo.pSampleMask,
+// can't be mapped to another member: its entry is left empty, so the member is not turned into a slice, and the formula
+// is returned for the doc comment. The formula may itself contain commas, so it is cut off before splitting the rest.
+func splitLenSpec(spec string) (lenSpecs []string, formula string) {
+	rest, isFormula := strings.CutPrefix(spec, "latexmath:")
+	if !isFormula {
+		return strings.Split(spec, ","), ""
+	}
+
+	end := strings.LastIndex(rest, "]")
+	if end < 0 {
+		return []string{""}, rest
+	}
+	formula = rest[:end+1]
+	lenSpecs = []string{""}
+	if tail := strings.TrimPrefix(rest[end+1:], ","); tail != "" {
+		lenSpecs = append(lenSpecs, strings.Split(tail, ",")...)
+	}
+	return lenSpecs, formula
+}
+
 // Group 1 match is numeric length, group 2 is enumeration
 var rxArrayLenSpec = regexp.MustCompile(`\[(\d+)\]|<enum>(\w+)</enum>`)
 
+// rxBitfieldWidth matches the width of a bitfield member, which follows its name, e.g. <name>mask</name>:8
+var rxBitfieldWidth = regexp.MustCompile(`</name>\s*:\s*(\d+)`)
+
 func newStructMemberFromXML(node *xmlquery.Node) *structMember {
 	rval := structMember{}
 	rval.registryName = xmlquery.FindOne(node, "name").InnerText()
@@ -494,25 +834,35 @@ func newStructMemberFromXML(node *xmlquery.Node) *structMember {
 	}
 	rval.pointerDepth = strings.Count(node.InnerText(), "*") - strings.Count(rval.comment, "*")
 
-	matches := rxArrayLenSpec.FindStringSubmatch(node.OutputXML(false))
-	if matches != nil {
+	// Multi-dimensional arrays, like VkTransformMatrixKHR.matrix[3][4], have one match per dimension
+	if allMatches := rxArrayLenSpec.FindAllStringSubmatch(node.OutputXML(false), -1); allMatches != nil {
 		rval.fixedLengthArray = true
-		if matches[1] != "" {
-			rval.lenSpecs = append(rval.lenSpecs, matches[1])
-		} else if matches[2] != "" {
-			rval.lenSpecs = append(rval.lenSpecs, matches[2])
-		} else {
-			panic("regexp unexpected matching in fixed length array")
+		for _, matches := range allMatches {
+			if matches[1] != "" {
+				rval.lenSpecs = append(rval.lenSpecs, matches[1])
+			} else if matches[2] != "" {
+				rval.lenSpecs = append(rval.lenSpecs, matches[2])
+			} else {
+				panic("regexp unexpected matching in fixed length array")
+			}
 		}
 	} else {
 		rval.lenSpecString = node.SelectAttr("len")
 		rval.altLenSpec = strings.ReplaceAll(node.SelectAttr("altlen"), "VK_", "")
 
-		rval.lenSpecs = strings.Split(rval.lenSpecString, ",")
+		rval.lenSpecs, rval.lenFormula = splitLenSpec(rval.lenSpecString)
 
 	}
 
 	rval.noAutoValidityFlag = node.SelectAttr("noautovalidity") == "true"
+	if m := rxBitfieldWidth.FindStringSubmatch(node.OutputXML(false)); m != nil {
+		rval.bitWidth, _ = strconv.Atoi(m[1])
+	}
+	if optAttr := node.SelectAttr("optional"); optAttr != "" {
+		for _, o := range strings.Split(optAttr, ",") {
+			rval.optional = append(rval.optional, o == "true")
+		}
+	}
 
 	// Pointers are a little odd. Generally a pointer in C becomes a slice in
 	// Go, and struct members have a related length member. But in certain
@@ -0,0 +1,310 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelfReferentialStruct(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantTypes []string // resolved along with the struct
+	}{
+		{"VkBaseOutStructure", []string{"VkBaseOutStructure", "VkStructureType"}},
+		{"VkTreeNode", []string{"VkTreeNode", "VkTreeLeaf", "uint32_t"}},
+		{"VkTreeLeaf", []string{"VkTreeNode", "VkTreeLeaf", "uint32_t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, structsFixture)
+
+			// Resolve must terminate, and report the struct and everything it points to
+			is := tr[tt.name].Resolve(tr, vr)
+			for _, n := range tt.wantTypes {
+				if is.ResolvedTypes[n] == nil {
+					t.Errorf("%s was not resolved", n)
+				}
+			}
+
+			// A second Resolve returns nothing new
+			if again := tr[tt.name].Resolve(tr, vr); len(again.ResolvedTypes) != 0 {
+				t.Errorf("second Resolve returned %d types", len(again.ResolvedTypes))
+			}
+		})
+	}
+
+	// The pointer cycles must also be declarable in Go
+	tr, vr := readTestRegistry(t, structsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkStructureType", "VkBaseOutStructure", "VkTreeNode", "VkTreeLeaf")
+	typeCheck(t, src, "", "fmt")
+}
+
+func TestOptionalMembers(t *testing.T) {
+	tr, vr := readTestRegistry(t, structsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkExtent2D", "VkRegionInfo")
+
+	tests := []struct {
+		member                     string
+		wantOptional, wantRequired bool
+	}{
+		{"pRequired", false, true},
+		{"pOptional", true, false},
+		// Whether it may be nil depends on other members
+		{"pUnchecked", false, false},
+		{"pOptionalOuter", true, false},
+		// Not pointers, so never documented as required
+		{"flags", true, false},
+		{"width", false, false},
+	}
+	members := make(map[string]*structMember)
+	for _, m := range tr["VkRegionInfo"].(*structType).members {
+		members[m.registryName] = m
+	}
+	for _, tt := range tests {
+		m := members[tt.member]
+		if m == nil {
+			t.Fatalf("%s: no such member", tt.member)
+		}
+		if m.IsOptional() != tt.wantOptional {
+			t.Errorf("%s: IsOptional is %v, want %v", tt.member, m.IsOptional(), tt.wantOptional)
+		}
+		decl := "// Must not be nil\n" + m.PublicName() + " "
+		if got := strings.Contains(src, decl); got != tt.wantRequired {
+			t.Errorf("%s: documented as required is %v, want %v in\n%s", tt.member, got, tt.wantRequired, src)
+		}
+	}
+}
+
+func TestEmptySliceMembers(t *testing.T) {
+	tr, vr := readTestRegistry(t, structsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkExtent2D", "VkExtentList")
+
+	out := runGenerated(t, src, `
+	for _, sl := range [][]Extent2D{nil, {}, {{Width: 1}, {Width: 2}}} {
+		v := (&ExtentList{PExtents: sl, PRequired: sl}).Vulkanize()
+		fmt.Println(v.extentCount, v.pExtents == nil, v.requiredCount, v.pRequired == nil)
+	}`, "fmt", "unsafe")
+
+	// An empty optional slice is passed as nil; an empty slice for a member without optional keeps its pointer
+	want := "0 true 0 true\n" +
+		"0 true 0 false\n" +
+		"2 false 2 false\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestSplitLenSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		wantSpecs   string // joined with |
+		wantFormula string
+	}{
+		{"count", "count", ""},
+		{"count,null-terminated", "count|null-terminated", ""},
+		{`latexmath:[\lceil{\mathit{rasterizationSamples} \over 32}\rceil]`, "", `[\lceil{\mathit{rasterizationSamples} \over 32}\rceil]`},
+		// The formula contains a comma, and more levels follow it
+		{`latexmath:[\mathit{a}, \mathit{b}],null-terminated`, "|null-terminated", `[\mathit{a}, \mathit{b}]`},
+		{`latexmath:unterminated`, "", "unterminated"},
+	}
+	for _, tt := range tests {
+		specs, formula := splitLenSpec(tt.spec)
+		if got := strings.Join(specs, "|"); got != tt.wantSpecs || formula != tt.wantFormula {
+			t.Errorf("%s: got %q, %q, want %q, %q", tt.spec, got, formula, tt.wantSpecs, tt.wantFormula)
+		}
+	}
+}
+
+const latexmathFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkSampleMask</name>;</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkCommandBuffer</name>)</type>
+	<type category="struct" name="VkPipelineMultisampleStateCreateInfo">
+		<member><type>uint32_t</type> <name>rasterizationSamples</name></member>
+		<member optional="true" len="latexmath:[\lceil{\mathit{rasterizationSamples} \over 32}\rceil]" altlen="(rasterizationSamples + 31) / 32">const <type>VkSampleMask</type>* <name>pSampleMask</name></member>
+	</type>
+	<type category="struct" name="VkSampleMaskInfo">
+		<member><type>uint32_t</type> <name>samples</name></member>
+		<member optional="true" len="latexmath:[\lceil{\mathit{samples} \over 32}\rceil]">const <type>VkSampleMask</type>* <name>pMask</name></member>
+	</type>
+</types>
+<commands>
+	<command>
+		<proto><type>void</type> <name>vkCmdSetSampleMaskTEST</name></proto>
+		<param><type>VkCommandBuffer</type> <name>commandBuffer</name></param>
+		<param><type>uint32_t</type> <name>samples</name></param>
+		<param len="latexmath:[\lceil{\mathit{samples} \over 32}\rceil]">const <type>VkSampleMask</type>* <name>pSampleMask</name></param>
+	</command>
+</commands>
+</registry>`
+
+func TestLatexmathLength(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		// The altlen form is easier to read than the formula
+		{"VkPipelineMultisampleStateCreateInfo", "// Length: (rasterizationSamples + 31) / 32\nPSampleMask *SampleMask\n"},
+		{"VkSampleMaskInfo", "// Length: [\\lceil{\\mathit{samples} \\over 32}\\rceil]\nPMask *SampleMask\n"},
+		{"vkCmdSetSampleMaskTEST", "func CmdSetSampleMaskTEST(commandBuffer CommandBuffer, samples uint32, sampleMask *SampleMask) ("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, latexmathFixture)
+			src := resolveAndPrint(t, tr, vr, tt.name)
+			if !strings.Contains(src, tt.want) {
+				t.Errorf("want %q in\n%s", tt.want, src)
+			}
+		})
+	}
+
+	// The members stay plain pointers, which Vulkanize and Goify pass through
+	tr, vr := readTestRegistry(t, latexmathFixture)
+	src := resolveAndPrint(t, tr, vr, "VkSampleMask", "VkPipelineMultisampleStateCreateInfo", "VkSampleMaskInfo")
+	typeCheck(t, src, "")
+}
+
+const featureChainFixture = `<registry>
+<types>
+	<type category="struct" name="VkPhysicalDeviceFeatures2" structextends="VkDeviceCreateInfo">
+		<member values="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2"><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>void</type>* <name>pNext</name></member>
+		<member><type>uint32_t</type> <name>robustBufferAccess</name></member>
+	</type>
+	<type category="struct" name="VkPhysicalDeviceVulkan11Features" structextends="VkPhysicalDeviceFeatures2,VkDeviceCreateInfo">
+		<member values="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_VULKAN_1_1_FEATURES"><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>void</type>* <name>pNext</name></member>
+		<member><type>uint32_t</type> <name>multiview</name></member>
+	</type>
+	<type category="struct" name="VkPhysicalDeviceMultiviewFeatures" structextends="VkPhysicalDeviceFeatures2,VkDeviceCreateInfo">
+		<member values="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_MULTIVIEW_FEATURES"><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>void</type>* <name>pNext</name></member>
+		<member><type>uint32_t</type> <name>multiview</name></member>
+	</type>
+	<type category="struct" name="VkPhysicalDeviceDynamicRenderingFeatures" structextends="VkPhysicalDeviceFeatures2,VkDeviceCreateInfo">
+		<member values="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_DYNAMIC_RENDERING_FEATURES"><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>void</type>* <name>pNext</name></member>
+		<member><type>uint32_t</type> <name>dynamicRendering</name></member>
+	</type>
+	<type category="struct" name="VkPhysicalDeviceDynamicRenderingFeaturesKHR" alias="VkPhysicalDeviceDynamicRenderingFeatures"/>
+	<type category="struct" name="VkPhysicalDeviceRayQueryFeaturesKHR" structextends="VkPhysicalDeviceFeatures2,VkDeviceCreateInfo">
+		<member values="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_RAY_QUERY_FEATURES_KHR"><type>VkStructureType</type> <name>sType</name></member>
+		<member><type>void</type>* <name>pNext</name></member>
+		<member><type>uint32_t</type> <name>rayQuery</name></member>
+	</type>
+	<type category="enum" name="VkStructureType"/>
+</types>
+<enums name="VkStructureType" type="enum">
+	<enum value="51" name="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_VULKAN_1_1_FEATURES"/>
+	<enum value="1000053001" name="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_MULTIVIEW_FEATURES"/>
+	<enum value="1000059000" name="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2"/>
+	<enum value="1000044003" name="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_DYNAMIC_RENDERING_FEATURES"/>
+	<enum value="1000348013" name="VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_RAY_QUERY_FEATURES_KHR"/>
+</enums>
+</registry>`
+
+var featureChainOrigins = map[string]string{
+	"VkPhysicalDeviceFeatures2":                   "VK_VERSION_1_1",
+	"VkPhysicalDeviceMultiviewFeatures":           "VK_VERSION_1_1",
+	"VkPhysicalDeviceVulkan11Features":            "VK_VERSION_1_2",
+	"VkPhysicalDeviceDynamicRenderingFeatures":    "VK_VERSION_1_3",
+	"VkPhysicalDeviceDynamicRenderingFeaturesKHR": "VK_KHR_dynamic_rendering",
+	"VkPhysicalDeviceRayQueryFeaturesKHR":         "VK_KHR_ray_query",
+}
+
+// featureChainStatics stands in for the parts of static_include that the feature chain uses
+const featureChainStatics = `
+type ChainNode interface {
+	StructureType() StructureType
+	NextPtr() *unsafe.Pointer
+	vulkanizeChainNode() unsafe.Pointer
+}
+
+type chainHeader struct {
+	sType StructureType
+	pNext unsafe.Pointer
+}
+
+func AppendNext(base, ext ChainNode) {
+	p := base.NextPtr()
+	for *p != nil {
+		p = &(*chainHeader)(*p).pNext
+	}
+	*p = ext.vulkanizeChainNode()
+}
+
+func makeApiVersion(variant, major, minor, patch uint32) uint32 {
+	return variant<<29 | major<<22 | minor<<12 | patch
+}
+
+var structureTypeOf = map[reflect.Type]StructureType{}
+`
+
+func TestFeatureChain(t *testing.T) {
+	tr, vr := readTestRegistry(t, featureChainFixture)
+	names := []string{"VkStructureType", "VkPhysicalDeviceFeatures2", "VkPhysicalDeviceVulkan11Features",
+		"VkPhysicalDeviceMultiviewFeatures", "VkPhysicalDeviceDynamicRenderingFeatures",
+		"VkPhysicalDeviceDynamicRenderingFeaturesKHR", "VkPhysicalDeviceRayQueryFeaturesKHR"}
+
+	sb := &strings.Builder{}
+	sb.WriteString(resolveAndPrint(t, tr, vr, names...))
+	sb.WriteString(featureChainStatics)
+	var types []TypeDefiner
+	for _, n := range names {
+		types = append(types, tr[n])
+	}
+	WriteFeatureChain(sb, types, func(n string) string { return featureChainOrigins[n] })
+
+	out := runGenerated(t, sb.String(), `
+	for _, c := range []struct {
+		major, minor uint32
+		exts         []string
+	}{
+		{1, 1, nil},
+		{1, 2, nil},
+		{1, 2, []string{"VK_KHR_dynamic_rendering", "VK_KHR_ray_query"}},
+		{1, 3, nil},
+	} {
+		chain := NewFeatureChain(makeApiVersion(0, c.major, c.minor, 0), c.exts...)
+		if chain.Vulkan11Features != nil {
+			chain.Vulkan11Features.Multiview = 1
+		}
+
+		// Walk the Vulkanized chain, printing the sType of each struct after the head
+		var sTypes []StructureType
+		for p := (*chainHeader)(chain.vulkanizeChainNode()).pNext; p != nil; p = (*chainHeader)(p).pNext {
+			sTypes = append(sTypes, (*chainHeader)(p).sType)
+		}
+		fmt.Println(chain.StructureType(), sTypes)
+	}`, "fmt", "reflect", "unsafe")
+
+	want := "VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2 [VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_MULTIVIEW_FEATURES]\n" +
+		// Multiview is promoted into Vulkan11Features, and the two must not be chained together
+		"VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2 [VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_VULKAN_1_1_FEATURES]\n" +
+		// Dynamic rendering is core in 1.3, but can be enabled on 1.2 through the KHR extension it was promoted from
+		"VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2 [VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_VULKAN_1_1_FEATURES VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_DYNAMIC_RENDERING_FEATURES VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_RAY_QUERY_FEATURES_KHR]\n" +
+		"VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_FEATURES_2 [VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_VULKAN_1_1_FEATURES VK_STRUCTURE_TYPE_PHYSICAL_DEVICE_DYNAMIC_RENDERING_FEATURES]\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestBool32Members(t *testing.T) {
+	tr, vr := readTestRegistry(t, structsFixture)
+	src := resolveAndPrint(t, tr, vr, "VkExampleFeatures") + readStatic(t, "static_basetype.go") + basetypeStubs
+
+	out := runGenerated(t, src, `	for _, b := range []Bool32{0, 1, 2, 0xFFFFFFFF} {
+		fmt.Println(b, (&_vkExampleFeatures{enabled: b}).Goify().Enabled)
+	}
+	fmt.Println((&ExampleFeatures{Enabled: true}).Vulkanize().enabled, (&ExampleFeatures{}).Vulkanize().enabled)`, "fmt")
+
+	// Only 0 is false, as in C
+	want := "0 false\n" +
+		"1 true\n" +
+		"2 true\n" +
+		"4294967295 true\n" +
+		"1 0\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -22,12 +22,13 @@ func _() {
 	_ = x[CatPointer-11]
 	_ = x[CatArray-12]
 	_ = x[CatCommand-13]
-	_ = x[CatMaximum-14]
+	_ = x[CatFuncPointer-14]
+	_ = x[CatMaximum-15]
 }
 
-const _TypeCategory_name = "CatNoneCatExtenCatDefineCatIncludeCatExternalCatHandleCatBasetypeCatEnumCatBitmaskCatStructCatUnionCatPointerCatArrayCatCommandCatMaximum"
+const _TypeCategory_name = "CatNoneCatExtenCatDefineCatIncludeCatExternalCatHandleCatBasetypeCatEnumCatBitmaskCatStructCatUnionCatPointerCatArrayCatCommandCatFuncPointerCatMaximum"
 
-var _TypeCategory_index = [...]uint8{0, 7, 15, 24, 34, 45, 54, 65, 72, 82, 91, 99, 109, 117, 127, 137}
+var _TypeCategory_index = [...]uint8{0, 7, 15, 24, 34, 45, 54, 65, 72, 82, 91, 99, 109, 117, 127, 141, 151}
 
 func (i TypeCategory) String() string {
 	if i < 0 || i >= TypeCategory(len(_TypeCategory_index)-1) {
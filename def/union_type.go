@@ -3,6 +3,7 @@ package def
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
@@ -14,6 +15,7 @@ type unionType struct {
 	structType
 
 	internalByteSize string
+	computedByteSize int // from the C layout of the members, 0 if it can't be computed
 }
 
 func (t *unionType) Category() TypeCategory { return CatUnion }
@@ -30,6 +32,10 @@ func (t *unionType) Resolve(tr TypeRegistry, vr ValueRegistry) *IncludeSet {
 	is.ResolvedTypes[t.registryName] = t
 	t.isResolved = true
 
+	if size, _, ok := cLayout(t, vr); ok {
+		t.computedByteSize = size
+	}
+
 	return is
 }
 
@@ -81,9 +87,15 @@ func (t *unionType) PrintInternalDeclaration(w io.Writer) {
 			Error("union is returned only, which is not yet handled in the binding")
 	}
 
-	// _vk type declaration
+	// _vk type declaration. A C union is as large as its largest member, rounded up to its strictest alignment, which
+	// cLayout computes. The size of the first member is a fallback for unions whose layout can't be computed.
 	var sizeString = t.internalByteSize
-	if t.internalByteSize == "" {
+	if sizeString == "" && t.computedByteSize > 0 {
+		sizeString = strconv.Itoa(t.computedByteSize)
+	}
+	if sizeString == "" {
+		logrus.WithField("registry type", t.registryName).
+			Warn("union layout can't be computed, sizing it by its first member; set go:internalSize in exceptions.json")
 		switch t.members[0].resolvedType.Category() { // updated with bugfix/issue-16
 		case CatPointer:
 			sizeString = fmt.Sprintf("unsafe.Sizeof((%s)(nil))", t.members[0].resolvedType.InternalName()) // Internal name will include the pointer and the underlying type
@@ -97,7 +109,13 @@ func (t *unionType) PrintInternalDeclaration(w io.Writer) {
 		}
 	}
 
-	fmt.Fprintf(w, "type %s [%s]byte\n", t.InternalName(), sizeString)
+	// The zero length arrays take no space, but give the type the alignment of its most strictly aligned member, as C
+	// does for unions. A plain byte array would be 1-aligned and could be placed at the wrong offset inside a struct.
+	fmt.Fprintf(w, "type %s struct {\n", t.InternalName())
+	for _, m := range t.members {
+		fmt.Fprintf(w, "  _ [0]%s\n", m.resolvedType.InternalName())
+	}
+	fmt.Fprintf(w, "  raw [%s]byte\n}\n", sizeString)
 
 	fmt.Fprintf(w, "func (u *%s) Vulkanize() *%s {\n", t.PublicName(), t.InternalName())
 	fmt.Fprintf(w, "  switch true {\n")
@@ -144,6 +162,7 @@ func newUnionTypeFromXML(node *xmlquery.Node, api string) *unionType {
 
 	rval.registryName = node.SelectAttr("name")
 	rval.isReturnedOnly = node.SelectAttr("returnedonly") == "true"
+	rval.comment = node.SelectAttr("comment")
 
 	queryString := fmt.Sprintf("member[(contains(@api,'%s') and not(@api='vulkansc')) or not(@api)]", api)
 	for _, mNode := range xmlquery.Find(node, queryString) {
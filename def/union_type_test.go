@@ -0,0 +1,72 @@
+package def
+
+import (
+	"strings"
+	"testing"
+)
+
+const unionFixture = `<registry>
+<types>
+	<type category="basetype">typedef <type>uint32_t</type> <name>VkBool32</name>;</type>
+	<type category="union" name="VkClearColorValue">
+		<member><type>float</type> <name>float32</name>[4]</member>
+		<member><type>int32_t</type> <name>int32</name>[4]</member>
+		<member><type>uint32_t</type> <name>uint32</name>[4]</member>
+	</type>
+	<type category="union" name="VkExampleValueData">
+		<member><type>uint32_t</type> <name>value32</name></member>
+		<member><type>uint64_t</type> <name>value64</name></member>
+		<member><type>float</type> <name>valueFloat</name></member>
+		<member><type>VkBool32</type> <name>valueBool</name></member>
+	</type>
+</types>
+</registry>`
+
+func TestUnionSize(t *testing.T) {
+	tests := []struct {
+		union, want string
+	}{
+		{"VkClearColorValue", "raw [16]byte"},
+		// The first member is 4 bytes, but the union is as large as value64
+		{"VkExampleValueData", "raw [8]byte"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.union, func(t *testing.T) {
+			tr, vr := readTestRegistry(t, unionFixture)
+			src := resolveAndPrint(t, tr, vr, tt.union)
+			if !strings.Contains(src, tt.want) {
+				t.Errorf("want %q in\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+func TestUnionAccessors(t *testing.T) {
+	tr, vr := readTestRegistry(t, unionFixture)
+	src := resolveAndPrint(t, tr, vr, "VkBool32", "VkClearColorValue", "VkExampleValueData")
+
+	out := runGenerated(t, src, `
+	var c ClearColorValue
+	c.AsTypeFloat32([4]float32{0.25, 0.5, 0.75, 1})
+	fmt.Println(unsafe.Sizeof(_vkClearColorValue{}), *(*[4]float32)(unsafe.Pointer(c.Vulkanize())))
+	c.AsTypeInt32([4]int32{1, -2, 3, -4})
+	fmt.Println(*(*[4]int32)(unsafe.Pointer(c.Vulkanize())))
+	c.AsTypeUint32([4]uint32{5, 6, 7, 8})
+	fmt.Println(*(*[4]uint32)(unsafe.Pointer(c.Vulkanize())))
+
+	var v ExampleValueData
+	v.AsValue32(7)
+	fmt.Println(unsafe.Sizeof(_vkExampleValueData{}), unsafe.Alignof(_vkExampleValueData{}), *(*uint32)(unsafe.Pointer(v.Vulkanize())))
+	v.AsValue64(1 << 40)
+	fmt.Println(*(*uint64)(unsafe.Pointer(v.Vulkanize())))
+	v.AsValueFloat(1.5)
+	fmt.Println(*(*float32)(unsafe.Pointer(v.Vulkanize())))
+	v.AsValueBool(true)
+	fmt.Println(*(*bool)(unsafe.Pointer(v.Vulkanize())))`, "fmt", "unsafe")
+
+	want := "16 [0.25 0.5 0.75 1]\n[1 -2 3 -4]\n[5 6 7 8]\n8 8 7\n1099511627776\n1.5\ntrue\n"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,245 @@
+package feat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bbredesen/vk-gen/def"
+)
+
+// ValueConflict describes a value name that was resolved more than once, either under several underlying types or
+// with different values.
+type ValueConflict struct {
+	RegistryName string
+	TypeNames    []string
+	Values       []string
+}
+
+// ValueConflictError is returned by CheckValueConflicts. Conflicts are sorted by registry name.
+type ValueConflictError struct {
+	Conflicts []ValueConflict
+}
+
+func (e *ValueConflictError) Error() string {
+	parts := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		parts[i] = fmt.Sprintf("%s (types: %s; values: %s)", c.RegistryName, strings.Join(c.TypeNames, ", "), strings.Join(c.Values, ", "))
+	}
+	return fmt.Sprintf("%d conflicting enum values: %s", len(e.Conflicts), strings.Join(parts, "; "))
+}
+
+// CheckValueConflicts looks for value names that appear under more than one type in ResolvedValues, or whose
+// definitions have different values. It should be called after Resolve (and any MergeResolved) and before the feature
+// is printed. The error is a *ValueConflictError, or nil if there are no conflicts.
+func (f *Feature) CheckValueConflicts() error {
+	typeNamesByValue := make(map[string][]string)
+	valuesByName := make(map[string]map[string]bool)
+
+	for typeName, vals := range f.ResolvedValues {
+		for k, v := range vals {
+			typeNamesByValue[k] = append(typeNamesByValue[k], typeName)
+			if valuesByName[k] == nil {
+				valuesByName[k] = make(map[string]bool)
+			}
+			valuesByName[k][v.ValueString()] = true
+		}
+	}
+
+	var conflicts []ValueConflict
+	for k, typeNames := range typeNamesByValue {
+		if len(typeNames) < 2 && len(valuesByName[k]) < 2 {
+			continue
+		}
+		values := make([]string, 0, len(valuesByName[k]))
+		for v := range valuesByName[k] {
+			values = append(values, v)
+		}
+		sort.Strings(typeNames)
+		sort.Strings(values)
+		conflicts = append(conflicts, ValueConflict{RegistryName: k, TypeNames: typeNames, Values: values})
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].RegistryName < conflicts[j].RegistryName })
+	return &ValueConflictError{Conflicts: conflicts}
+}
+
+// UntypedValue is a resolved value whose underlying type is not resolved, so its declaration would refer to a Go type
+// that is never generated
+type UntypedValue struct {
+	RegistryName, TypeName string
+}
+
+// CheckValueTypes returns the resolved values whose underlying type is not in ResolvedTypes, sorted by registry name.
+// Types resolved by any of the base features, like the core feature for a platform, are also accepted, as are types
+// that are never declared in the output (external types such as uint32_t). It should be called after Resolve. Missing
+// types are reported rather than resolved here, since pulling in a type would also pull in its values and dependencies.
+func (f *Feature) CheckValueTypes(base ...*Feature) []UntypedValue {
+	declared := func(typeName string, vals def.ValueRegistry) bool {
+		if _, found := f.ResolvedTypes[typeName]; found {
+			return true
+		}
+		for _, b := range base {
+			if _, found := b.ResolvedTypes[typeName]; found {
+				return true
+			}
+		}
+		for _, v := range vals {
+			if td := v.ResolvedType(); td != nil && td.RegistryName() == typeName {
+				return td.Category() == def.CatExternal || td.Category() == def.CatNone
+			}
+		}
+		return false
+	}
+
+	var rval []UntypedValue
+	for typeName, vals := range f.ResolvedValues {
+		if declared(typeName, vals) {
+			continue
+		}
+		for k := range vals {
+			rval = append(rval, UntypedValue{RegistryName: k, TypeName: typeName})
+		}
+	}
+	sort.Slice(rval, func(i, j int) bool { return rval[i].RegistryName < rval[j].RegistryName })
+	return rval
+}
+
+// NameCollision records a type or value that was renamed because its Go name was already used by another resolved
+// symbol
+type NameCollision struct {
+	RegistryName, CollidesWith string
+	GoName, NewGoName          string
+}
+
+// RejectedName is a new name from a NameTransform that ApplyNameTransform did not apply, because the static files refer
+// to the type or value by its default name (def.StaticRegistryNames) or declare GoName themselves
+// (def.StaticIdentifiers).
+type RejectedName struct {
+	RegistryName, GoName string
+}
+
+func (r RejectedName) Error() string {
+	if def.StaticRegistryNames[r.RegistryName] {
+		return fmt.Sprintf("%s: the static files depend on its default name, not renaming it to %s", r.RegistryName, r.GoName)
+	}
+	return fmt.Sprintf("%s: %s is declared by the static files, not renaming it", r.RegistryName, r.GoName)
+}
+
+// ApplyNameTransform renames the resolved types and values of f with fn. Only names that were derived from the
+// registry name are replaced, so names set in exceptions.json, types mapped to Go types and short value names are
+// kept. References to a type or value are printed with its public name, so they follow the new name. It must run
+// after Resolve and before RenameCollisions, which then catches any collisions the new names introduce. New names that
+// would break the static files keep the default name and are returned, sorted by registry name.
+func (f *Feature) ApplyNameTransform(fn def.NameTransform) []RejectedName {
+	var rejected []RejectedName
+	check := func(registryName, defaultName, newName string) bool {
+		if newName == defaultName {
+			return false
+		}
+		if def.StaticRegistryNames[registryName] || def.StaticIdentifiers[newName] {
+			rejected = append(rejected, RejectedName{RegistryName: registryName, GoName: newName})
+			return false
+		}
+		return true
+	}
+
+	for _, td := range f.ResolvedTypes {
+		switch td.Category() {
+		case def.CatNone, def.CatExternal, def.CatInclude, def.CatPointer, def.CatArray:
+			continue
+		}
+		r, ok := td.(def.Renamer)
+		if !ok || td.PublicName() != def.RenameIdentifier(td.RegistryName()) {
+			continue
+		}
+		if name := fn(td.RegistryName(), td.Category()); check(td.RegistryName(), td.PublicName(), name) {
+			r.SetPublicName(name)
+		}
+	}
+
+	for _, vr := range f.ResolvedValues {
+		for _, vd := range vr {
+			r, ok := vd.(def.Renamer)
+			if !ok || vd.PublicName() != def.RenameIdentifier(vd.RegistryName()) {
+				continue
+			}
+			cat := def.CatNone
+			if vd.ResolvedType() != nil {
+				cat = vd.ResolvedType().Category()
+			}
+			if name := fn(vd.RegistryName(), cat); check(vd.RegistryName(), vd.PublicName(), name) {
+				r.SetPublicName(name)
+			}
+		}
+	}
+
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].RegistryName < rejected[j].RegistryName })
+	return rejected
+}
+
+// RenameCollisions checks the public names of all resolved types and values for duplicates and renames the later
+// ones with a numbered suffix, see def.ResolveNameCollisions. Types come before values, each sorted by registry name,
+// so a type keeps its name over a value. Types that are declared as Go builtins or are never declared (external,
+// include, pointer and array types) are not checked. It must run after Resolve and before printing.
+func (f *Feature) RenameCollisions() []NameCollision {
+	var names []def.NamePair
+	renamers := make(map[string]def.Renamer)
+	for _, td := range f.SortedTypes() {
+		switch td.Category() {
+		case def.CatNone, def.CatExternal, def.CatInclude, def.CatPointer, def.CatArray:
+			continue
+		}
+		if name := td.PublicName(); name != "" && unicode.IsUpper([]rune(name)[0]) {
+			names = append(names, def.NamePair{RegistryName: td.RegistryName(), GoName: name})
+			if r, ok := td.(def.Renamer); ok {
+				renamers[td.RegistryName()] = r
+			}
+		}
+	}
+
+	var vals []def.ValueDefiner
+	for _, vr := range f.ResolvedValues {
+		for _, v := range vr {
+			vals = append(vals, v)
+		}
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i].RegistryName() < vals[j].RegistryName() })
+	for _, v := range vals {
+		names = append(names, def.NamePair{RegistryName: v.RegistryName(), GoName: v.PublicName()})
+		if r, ok := v.(def.Renamer); ok {
+			renamers[v.RegistryName()] = r
+		}
+	}
+
+	firstClaim := make(map[string]string)
+	for _, n := range names {
+		if _, found := firstClaim[n.GoName]; !found {
+			firstClaim[n.GoName] = n.RegistryName
+		}
+	}
+
+	var rval []NameCollision
+	renames := def.ResolveNameCollisions(names)
+	for _, n := range names {
+		newName, found := renames[n.RegistryName]
+		if !found {
+			continue
+		}
+		if r, ok := renamers[n.RegistryName]; ok {
+			r.SetPublicName(newName)
+		}
+		rval = append(rval, NameCollision{
+			RegistryName: n.RegistryName,
+			CollidesWith: firstClaim[n.GoName],
+			GoName:       n.GoName,
+			NewGoName:    newName,
+		})
+	}
+
+	return rval
+}
@@ -0,0 +1,122 @@
+package feat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bbredesen/vk-gen/def"
+)
+
+const nameCollisionFixture = `<registry>
+<types>
+	<type requires="vk_platform" name="uint32_t"/>
+	<type category="struct" name="VkPoint"><member><type>uint32_t</type> <name>x</name></member></type>
+	<type category="struct" name="VkExtent2D"><member><type>uint32_t</type> <name>width</name></member></type>
+</types>
+<enums name="API Constants">
+	<enum type="uint32_t" value="1" name="VK_Point"/>
+	<enum type="uint32_t" value="2" name="VK_Extent"/>
+</enums>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkPoint"/>
+		<type name="VkExtent2D"/>
+		<enum name="VK_Point"/>
+		<enum name="VK_Extent"/>
+	</require>
+</feature>
+</registry>`
+
+func TestRenameCollisions(t *testing.T) {
+	tr, vr, f := readResolvedFeature(t, nameCollisionFixture, "VK_VERSION_1_0")
+
+	// The struct keeps its name over the constant
+	got := f.RenameCollisions()
+	want := []NameCollision{{RegistryName: "VK_Point", CollidesWith: "VkPoint", GoName: "Point", NewGoName: "Point_2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := tr["VkPoint"].PublicName(); got != "Point" {
+		t.Errorf("VkPoint: got %s, want Point", got)
+	}
+	for name, want := range map[string]string{"VK_Point": "Point_2", "VK_Extent": "Extent"} {
+		if got := vr[name].PublicName(); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+
+	// Nothing is left to rename
+	if again := f.RenameCollisions(); len(again) != 0 {
+		t.Errorf("second pass renamed %+v", again)
+	}
+
+	// The new names are kept on the registry's values, so a fresh registry starts with the default names
+	_, vr2, _ := readResolvedFeature(t, nameCollisionFixture, "VK_VERSION_1_0")
+	if got := vr2["VK_Point"].PublicName(); got != "Point" {
+		t.Errorf("VK_Point in another registry: got %s, want Point", got)
+	}
+}
+
+const nameTransformFixture = `<registry>
+<types>
+	<type category="enum" name="VkResult"/>
+	<type category="struct" name="VkExtent2D">
+		<member><type>uint32_t</type> <name>width</name></member>
+	</type>
+	<type category="struct" name="VkOffset2D">
+		<member><type>int32_t</type> <name>x</name></member>
+	</type>
+</types>
+<enums name="VkResult" type="enum">
+	<enum value="0" name="VK_SUCCESS"/>
+	<enum value="1" name="VK_NOT_READY"/>
+</enums>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkResult"/>
+		<type name="VkExtent2D"/>
+		<type name="VkOffset2D"/>
+	</require>
+</feature>
+</registry>`
+
+func TestApplyNameTransform(t *testing.T) {
+	tr, vr, f := readResolvedFeature(t, nameTransformFixture, "VK_VERSION_1_0")
+
+	rejected := f.ApplyNameTransform(func(vkName string, cat def.TypeCategory) string {
+		if vkName == "VkOffset2D" {
+			return "ChainNode"
+		}
+		return "Vk" + def.DefaultNameTransform(vkName, cat)
+	})
+
+	wantRejected := []RejectedName{
+		{"VK_SUCCESS", "VkSUCCESS"},
+		{"VkOffset2D", "ChainNode"},
+		{"VkResult", "VkResult"},
+	}
+	if !reflect.DeepEqual(rejected, wantRejected) {
+		t.Errorf("rejected: got %v, want %v", rejected, wantRejected)
+	}
+
+	tests := []struct {
+		registryName, want string
+	}{
+		{"VkExtent2D", "VkExtent2D"},
+		{"VkOffset2D", "Offset2D"},
+		{"VkResult", "Result"},
+		{"VK_NOT_READY", "VkNOT_READY"},
+		{"VK_SUCCESS", "SUCCESS"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if td := tr[tt.registryName]; td != nil {
+			got = td.PublicName()
+		} else {
+			got = vr[tt.registryName].PublicName()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.registryName, got, tt.want)
+		}
+	}
+}
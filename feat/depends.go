@@ -0,0 +1,176 @@
+package feat
+
+import (
+	"fmt"
+	"strings"
+)
+
+type dependsOp int
+
+const (
+	dependsName dependsOp = iota
+	dependsOr
+	dependsAnd
+)
+
+// dependsExpr is a node in the parsed tree of a "depends" attribute. vk.xml uses ',' for OR, '+' for AND and
+// parentheses for grouping, e.g. "(VK_VERSION_1_1,VK_KHR_get_physical_device_properties2)+VK_KHR_maintenance3".
+// Leaf nodes carry the name of a feature or extension, interior nodes carry their operands.
+type dependsExpr struct {
+	op       dependsOp
+	name     string
+	operands []*dependsExpr
+}
+
+func (e *dependsExpr) String() string {
+	switch e.op {
+	case dependsName:
+		return e.name
+	case dependsOr, dependsAnd:
+		sep := ","
+		if e.op == dependsAnd {
+			sep = "+"
+		}
+		parts := make([]string, len(e.operands))
+		for i, o := range e.operands {
+			parts[i] = o.String()
+			if len(o.operands) > 1 {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, sep)
+	}
+	return ""
+}
+
+// satisfiedBy reports whether the expression holds when exactly the named features and extensions are present
+func (e *dependsExpr) satisfiedBy(names map[string]bool) bool {
+	switch e.op {
+	case dependsName:
+		return names[e.name]
+	case dependsOr:
+		for _, o := range e.operands {
+			if o.satisfiedBy(names) {
+				return true
+			}
+		}
+		return false
+	case dependsAnd:
+		for _, o := range e.operands {
+			if !o.satisfiedBy(names) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// parseDepends parses a depends expression into a tree. '+' binds tighter than ',', although the registry always
+// parenthesizes mixed expressions. An empty string returns a nil expression.
+func parseDepends(s string) (*dependsExpr, error) {
+	p := &dependsParser{tokens: tokenizeDepends(s)}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q at position %d in depends expression %q", p.tokens[p.pos], p.pos, s)
+	}
+	return expr, nil
+}
+
+func tokenizeDepends(s string) []string {
+	var rval []string
+	var sb strings.Builder
+
+	flush := func() {
+		if name := strings.TrimSpace(sb.String()); name != "" {
+			rval = append(rval, name)
+		}
+		sb.Reset()
+	}
+
+	for _, r := range s {
+		switch r {
+		case ',', '+', '(', ')':
+			flush()
+			rval = append(rval, string(r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+
+	return rval
+}
+
+type dependsParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *dependsParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *dependsParser) parseOr() (*dependsExpr, error) {
+	return p.parseBinary(dependsOr, ",", p.parseAnd)
+}
+
+func (p *dependsParser) parseAnd() (*dependsExpr, error) {
+	return p.parseBinary(dependsAnd, "+", p.parseTerm)
+}
+
+func (p *dependsParser) parseBinary(op dependsOp, sep string, next func() (*dependsExpr, error)) (*dependsExpr, error) {
+	first, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*dependsExpr{first}
+	for p.peek() == sep {
+		p.pos++
+		o, err := next()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, o)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &dependsExpr{op: op, operands: operands}, nil
+}
+
+func (p *dependsParser) parseTerm() (*dependsExpr, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of depends expression")
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	case ",", "+", ")":
+		return nil, fmt.Errorf("unexpected token %q at position %d", tok, p.pos)
+	default:
+		p.pos++
+		return &dependsExpr{op: dependsName, name: tok}, nil
+	}
+}
@@ -0,0 +1,127 @@
+package feat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDepends(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // String() of the parsed expression, "" for nil
+		wantErr bool
+	}{
+		{"", "", false},
+		{"VK_VERSION_1_1", "VK_VERSION_1_1", false},
+		{" VK_VERSION_1_1 ", "VK_VERSION_1_1", false},
+		{"VK_VERSION_1_1,VK_KHR_maintenance1", "VK_VERSION_1_1,VK_KHR_maintenance1", false},
+		{"VK_KHR_a+VK_KHR_b+VK_KHR_c", "VK_KHR_a+VK_KHR_b+VK_KHR_c", false},
+		{"(VK_VERSION_1_1,VK_KHR_get_physical_device_properties2)+VK_KHR_maintenance3", "(VK_VERSION_1_1,VK_KHR_get_physical_device_properties2)+VK_KHR_maintenance3", false},
+		// '+' binds tighter than ','
+		{"VK_KHR_a,VK_KHR_b+VK_KHR_c", "VK_KHR_a,(VK_KHR_b+VK_KHR_c)", false},
+		{"((VK_KHR_a))", "VK_KHR_a", false},
+		{"VK_KHR_a,", "", true},
+		{"+VK_KHR_a", "", true},
+		{"(VK_KHR_a,VK_KHR_b", "", true},
+		{"VK_KHR_a)", "", true},
+		{"VK_KHR_a()", "", true},
+	}
+	for _, tt := range tests {
+		expr, err := parseDepends(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: got error %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		got := ""
+		if expr != nil {
+			got = expr.String()
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDependsSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		depends string
+		names   string // comma separated names that are present
+		want    bool
+	}{
+		{"VK_KHR_a", "VK_KHR_a", true},
+		{"VK_KHR_a", "VK_KHR_b", false},
+		{"VK_KHR_a,VK_KHR_b", "VK_KHR_b", true},
+		{"VK_KHR_a+VK_KHR_b", "VK_KHR_b", false},
+		{"VK_KHR_a+VK_KHR_b", "VK_KHR_a,VK_KHR_b", true},
+		{"(VK_VERSION_1_1,VK_KHR_a)+VK_KHR_b", "VK_VERSION_1_1,VK_KHR_b", true},
+		{"(VK_VERSION_1_1,VK_KHR_a)+VK_KHR_b", "VK_VERSION_1_1", false},
+		{"(VK_VERSION_1_1,VK_KHR_a)+VK_KHR_b", "VK_KHR_a,VK_KHR_b", true},
+	}
+	for _, tt := range tests {
+		expr, err := parseDepends(tt.depends)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.depends, err)
+		}
+		names := make(map[string]bool)
+		for _, n := range strings.Split(tt.names, ",") {
+			names[n] = true
+		}
+		if got := expr.satisfiedBy(names); got != tt.want {
+			t.Errorf("%q with %s: got %v, want %v", tt.depends, tt.names, got, tt.want)
+		}
+	}
+}
+
+// dependsFixture is formatted with the depends attribute of VK_VERSION_1_2 and the supported attribute of VK_KHR_a
+const dependsFixture = `<registry>
+<types>
+	<type category="struct" name="VkA"><member><type>uint32_t</type> <name>a</name></member></type>
+	<type category="struct" name="VkB"><member><type>uint32_t</type> <name>b</name></member></type>
+	<type category="struct" name="VkBase"><member><type>uint32_t</type> <name>base</name></member></type>
+	<type category="struct" name="VkEleven"><member><type>uint32_t</type> <name>eleven</name></member></type>
+	<type category="struct" name="VkTop"><member><type>uint32_t</type> <name>top</name></member></type>
+</types>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require><type name="VkBase"/></require>
+</feature>
+<feature api="vulkan" name="VK_VERSION_1_1" number="1.1">
+	<require><type name="VkEleven"/></require>
+</feature>
+<feature api="vulkan" name="VK_VERSION_1_2" number="1.2" depends="%s">
+	<require><type name="VkTop"/></require>
+</feature>
+<extensions>
+	<extension name="VK_KHR_a" number="1" supported="%s">
+		<require><type name="VkA"/></require>
+	</extension>
+	<extension name="VK_KHR_b" number="2" supported="vulkan">
+		<require><type name="VkB"/></require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestReadFeatureDepends(t *testing.T) {
+	tests := []struct {
+		name, depends, aSupported string
+		want                      string // sorted resolved type names
+	}{
+		{"single name", "VK_VERSION_1_0", "vulkan", "VkBase,VkTop"},
+		{"and", "VK_VERSION_1_0+VK_VERSION_1_1", "vulkan", "VkBase,VkEleven,VkTop"},
+		{"or reads the first alternative", "VK_VERSION_1_1,VK_VERSION_1_0", "vulkan", "VkEleven,VkTop"},
+		{"or skips a missing alternative", "VK_VERSION_9_9,VK_VERSION_1_0", "vulkan", "VkBase,VkTop"},
+		{"nested", "VK_VERSION_1_0+(VK_VERSION_9_9,VK_VERSION_1_1)", "vulkan", "VkBase,VkEleven,VkTop"},
+		{"missing dependency", "VK_VERSION_1_0+VK_VERSION_9_9", "vulkan", "VkBase,VkTop"},
+		{"extensions", "VK_VERSION_1_0+VK_KHR_a+VK_KHR_b", "vulkan", "VkA,VkB,VkBase,VkTop"},
+		{"or reads the first extension", "VK_VERSION_1_0+(VK_KHR_a,VK_KHR_b)", "vulkan", "VkA,VkBase,VkTop"},
+		{"or skips an unsupported extension", "VK_VERSION_1_0+(VK_KHR_a,VK_KHR_b)", "disabled", "VkB,VkBase,VkTop"},
+		{"unparseable expression", "VK_VERSION_1_0+", "vulkan", "VkTop"},
+	}
+	for _, tt := range tests {
+		xml := strings.Replace(dependsFixture, "%s", tt.depends, 1)
+		xml = strings.Replace(xml, "%s", tt.aSupported, 1)
+		_, _, f := readResolvedFeature(t, xml, "VK_VERSION_1_2")
+		if got := resolvedVkNames(f); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
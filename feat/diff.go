@@ -0,0 +1,64 @@
+package feat
+
+import "sort"
+
+// FeatureDiff lists the names that differ between two features. Names are sorted.
+type FeatureDiff struct {
+	AddedTypes, RemovedTypes   []string
+	AddedValues, RemovedValues []string
+}
+
+// IsEmpty returns true if the two features had the same types and values
+func (d FeatureDiff) IsEmpty() bool {
+	return len(d.AddedTypes) == 0 && len(d.RemovedTypes) == 0 && len(d.AddedValues) == 0 && len(d.RemovedValues) == 0
+}
+
+// Diff compares f against g, reporting names that are in g but not in f as added and names that are in f but not in
+// g as removed. Both required and resolved names are compared, so features can be diffed before or after Resolve.
+func (f *Feature) Diff(g *Feature) FeatureDiff {
+	fTypes, gTypes := f.typeNameSet(), g.typeNameSet()
+	fValues, gValues := f.valueNameSet(), g.valueNameSet()
+
+	return FeatureDiff{
+		AddedTypes:    setDifference(gTypes, fTypes),
+		RemovedTypes:  setDifference(fTypes, gTypes),
+		AddedValues:   setDifference(gValues, fValues),
+		RemovedValues: setDifference(fValues, gValues),
+	}
+}
+
+func (f *Feature) typeNameSet() map[string]bool {
+	rval := make(map[string]bool)
+	for k := range f.requireTypeNames {
+		rval[k] = true
+	}
+	for k := range f.ResolvedTypes {
+		rval[k] = true
+	}
+	return rval
+}
+
+func (f *Feature) valueNameSet() map[string]bool {
+	rval := make(map[string]bool)
+	for k := range f.requireValueNames {
+		rval[k] = true
+	}
+	for _, vr := range f.ResolvedValues {
+		for k := range vr {
+			rval[k] = true
+		}
+	}
+	return rval
+}
+
+// setDifference returns the sorted names in a that are not in b
+func setDifference(a, b map[string]bool) []string {
+	rval := make([]string, 0)
+	for k := range a {
+		if !b[k] {
+			rval = append(rval, k)
+		}
+	}
+	sort.Strings(rval)
+	return rval
+}
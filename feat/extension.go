@@ -1,67 +1,88 @@
 package feat
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/antchfx/xmlquery"
 	"github.com/bbredesen/vk-gen/def"
+	"github.com/sirupsen/logrus"
 )
 
 type Extension struct {
 	extensionName                   string
 	extensionNumber                 string
 	supportedString, platformString string
+	provisional                     bool
 
 	*Feature
 
 	requireExtensionNames map[string]bool
 }
 
-func ReadExtensionFromXML(extNode *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry) *Extension {
+// ReadExtensionFromXML reads an <extension> node. Only the require blocks for the filter's API are read; a nil filter
+// reads them all.
+func ReadExtensionFromXML(extNode *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter) *Extension {
 	rval := Extension{
 		extensionName:         extNode.SelectAttr("name"),
 		extensionNumber:       extNode.SelectAttr("number"),
 		supportedString:       extNode.SelectAttr("supported"),
 		platformString:        extNode.SelectAttr("platform"),
+		provisional:           extNode.SelectAttr("provisional") == "true",
 		requireExtensionNames: make(map[string]bool),
 		Feature:               NewFeature(),
 	}
 
-	for _, reqNode := range xmlquery.Find(extNode, "/require") {
-		for _, typeNode := range xmlquery.Find(reqNode, "/type") { //} or /command") {
-			rval.requireTypeNames[typeNode.SelectAttr("name")] = true
-		}
+	extNum, err := strconv.Atoi(rval.extensionNumber)
+	if err != nil {
+		logrus.WithField("extension", rval.extensionName).
+			WithField("number", rval.extensionNumber).
+			WithError(err).
+			Error("could not convert extension number, enum offsets will be incorrect")
+	}
 
-		for _, cmdNode := range xmlquery.Find(reqNode, "/command") {
-			rval.requireTypeNames[cmdNode.SelectAttr("name")] = true
-		}
+	// Provisional extensions are generated with the provisional platform, behind its build tag, even if the registry
+	// does not list them under it
+	if rval.provisional && rval.platformString == "" {
+		rval.platformString = "provisional"
+	}
 
-		for _, enumNode := range xmlquery.Find(reqNode, "/enum") {
-			extendsTypeName := enumNode.SelectAttr("extends")
+	rval.featureName = rval.extensionName
+	rval.includedFeatures[rval.extensionName] = true
+	if filter != nil {
+		rval.logger = filter.Logger
+	}
 
-			if extendsTypeName == "" && enumNode.SelectAttr("value") == "" && enumNode.SelectAttr("alias") == "" {
-				// Some extensions are actually requiring an outside constant, like VK_SHADER_UNUSED_KHR; These
-				// should already be in the registry as external types
-				rval.requireValueNames[enumNode.SelectAttr("name")] = true
-				continue
-			}
+	for _, reqNode := range xmlquery.Find(extNode, "/require") {
+		typeNames, enumNodes := readBlockEntries(reqNode, filter)
+		requireTypes, requireValues := rval.requireTargets(reqNode)
+		for _, n := range typeNames {
+			requireTypes[n] = true
+		}
 
-			extNum, err := strconv.Atoi(rval.extensionNumber)
-			if err != nil {
-				panic(err)
+		for _, enumNode := range enumNodes {
+			// Some extensions are actually requiring an outside constant, like VK_SHADER_UNUSED_KHR; These
+			// should already be in the registry as external types
+			if vd := newRequiredValueFromXML(enumNode, tr, extNum); vd != nil {
+				registerExtendedValue(vr, vd, rval.extensionName)
 			}
 
-			var vd def.ValueDefiner
+			requireValues[enumNode.SelectAttr("name")] = true
+		}
+	}
 
-			if td, found := tr[extendsTypeName]; found {
-				vd = def.NewEnumValueFromXML(td, enumNode)
-			} else {
-				vd = def.NewUntypedEnumValueFromXML(enumNode)
+	rval.claimRequired()
+	rval.requiredExtensions[rval.extensionName] = extNode.SelectAttr("type")
+	rval.deprecatedBy = extNode.SelectAttr("deprecatedby")
+	rval.readDeprecationsFromXML(extNode, filter)
+
+	// Names from a promoted extension are kept as aliases of the core names
+	if promotedTo := extNode.SelectAttr("promotedto"); promotedTo != "" && rval.deprecatedBy == "" {
+		for k := range rval.requireTypeNames {
+			rval.deprecations[k] = deprecation{
+				note:        fmt.Sprintf("%s was promoted to %s, use the aliased name instead.", rval.extensionName, promotedTo),
+				aliasesOnly: true,
 			}
-			vd.SetExtensionNumber(extNum)
-			vr[vd.RegistryName()] = vd
-
-			rval.requireValueNames[enumNode.SelectAttr("name")] = true
 		}
 	}
 
@@ -70,3 +91,7 @@ func ReadExtensionFromXML(extNode *xmlquery.Node, tr def.TypeRegistry, vr def.Va
 
 func (e *Extension) Name() string         { return e.extensionName }
 func (e *Extension) PlatformName() string { return e.platformString }
+
+// IsProvisional reports whether the extension is marked provisional="true", i.e. a beta extension whose interface may
+// still change
+func (e *Extension) IsProvisional() bool { return e.provisional }
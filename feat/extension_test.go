@@ -0,0 +1,230 @@
+package feat
+
+import "testing"
+
+const extensionValuesFixture = `<registry>
+<types>
+	<type category="enum" name="VkResult"/>
+	<type category="enum" name="VkFormat"/>
+</types>
+<enums name="VkResult" type="enum">
+	<enum value="0" name="VK_SUCCESS"/>
+	<enum value="-1" name="VK_ERROR_OUT_OF_HOST_MEMORY"/>
+</enums>
+<enums name="VkFormat" type="enum">
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+</enums>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkResult"/>
+		<type name="VkFormat"/>
+	</require>
+</feature>
+<feature api="vulkan" name="VK_VERSION_1_1" number="1.1" depends="VK_VERSION_1_0">
+	<require>
+		<enum extends="VkResult" extnumber="70" offset="0" dir="-" name="VK_ERROR_OUT_OF_POOL_MEMORY"/>
+	</require>
+</feature>
+<extensions>
+	<extension name="VK_KHR_swapchain" number="2" supported="vulkan">
+		<require>
+			<enum value="70" name="VK_KHR_SWAPCHAIN_SPEC_VERSION"/>
+			<enum offset="3" extends="VkResult" name="VK_SUBOPTIMAL_KHR"/>
+			<enum offset="4" extends="VkResult" dir="-" name="VK_ERROR_OUT_OF_DATE_KHR"/>
+		</require>
+	</extension>
+	<extension name="VK_KHR_maintenance1" number="70" supported="vulkan" promotedto="VK_VERSION_1_1">
+		<require>
+			<enum extends="VkResult" name="VK_ERROR_OUT_OF_POOL_MEMORY_KHR" alias="VK_ERROR_OUT_OF_POOL_MEMORY"/>
+		</require>
+	</extension>
+	<extension name="VK_EXT_other" number="9" supported="vulkan">
+		<require>
+			<enum extends="VkResult" extnumber="2" offset="5" dir="-" name="VK_ERROR_FROM_SWAPCHAIN"/>
+		</require>
+	</extension>
+	<!-- Not a number, so only values that don't need it are correct -->
+	<extension name="VK_EXT_unnumbered" number="x" supported="vulkan">
+		<require>
+			<enum value="1" name="VK_EXT_UNNUMBERED_SPEC_VERSION"/>
+			<enum extends="VkResult" extnumber="2" offset="6" dir="-" name="VK_ERROR_FROM_UNNUMBERED"/>
+		</require>
+	</extension>
+	<!-- The aliases come before their target in document order, and the target is in an extension that is not read -->
+	<extension name="VK_EXT_first" number="10" supported="vulkan">
+		<require>
+			<enum extends="VkFormat" name="VK_FORMAT_B_ALIAS_EXT" alias="VK_FORMAT_B_IMG"/>
+			<enum extends="VkFormat" name="VK_FORMAT_B_ALIAS2_EXT" alias="VK_FORMAT_B_ALIAS_EXT"/>
+		</require>
+	</extension>
+	<extension name="VK_IMG_second" number="55" supported="vulkan">
+		<require>
+			<enum offset="2" extends="VkFormat" name="VK_FORMAT_B_IMG"/>
+		</require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestExtensionEnumValues(t *testing.T) {
+	tests := []struct {
+		feature, value, want string
+	}{
+		{"VK_VERSION_1_1", "VK_ERROR_OUT_OF_POOL_MEMORY", "-1000069000"},
+		{"VK_KHR_maintenance1", "VK_ERROR_OUT_OF_POOL_MEMORY_KHR", "ERROR_OUT_OF_POOL_MEMORY"},
+		{"VK_KHR_swapchain", "VK_SUBOPTIMAL_KHR", "1000001003"},
+		{"VK_KHR_swapchain", "VK_ERROR_OUT_OF_DATE_KHR", "-1000001004"},
+		{"VK_KHR_swapchain", "VK_KHR_SWAPCHAIN_SPEC_VERSION", "70"},
+		// extnumber takes precedence over the number of the extension adding the value
+		{"VK_EXT_other", "VK_ERROR_FROM_SWAPCHAIN", "-1000001005"},
+		// An extension number that can't be converted is logged rather than a panic
+		{"VK_EXT_unnumbered", "VK_EXT_UNNUMBERED_SPEC_VERSION", "1"},
+		{"VK_EXT_unnumbered", "VK_ERROR_FROM_UNNUMBERED", "-1000001006"},
+	}
+	for _, tt := range tests {
+		_, vr, f := readResolvedFeature(t, extensionValuesFixture, tt.feature)
+		vd := vr[tt.value]
+		if vd == nil {
+			t.Errorf("%s: not in the registry", tt.value)
+			continue
+		}
+		if got := vd.ValueString(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.value, got, tt.want)
+		}
+		if f.OriginOf(tt.value) != tt.feature {
+			t.Errorf("%s: introduced by %q, want %s", tt.value, f.OriginOf(tt.value), tt.feature)
+		}
+	}
+}
+
+func TestExtensionValueAliasOrder(t *testing.T) {
+	_, _, f := readResolvedFeature(t, extensionValuesFixture, "VK_EXT_first")
+	values := f.ResolvedValues["VkFormat"]
+
+	tests := []struct {
+		name, want string
+	}{
+		{"VK_FORMAT_B_ALIAS_EXT", "FORMAT_B_IMG"},
+		{"VK_FORMAT_B_ALIAS2_EXT", "FORMAT_B_ALIAS_EXT"},
+		// Pulled in as the target of the alias, with the value computed from its own extension's number
+		{"VK_FORMAT_B_IMG", "1000054002"},
+	}
+	for _, tt := range tests {
+		vd := values[tt.name]
+		if vd == nil {
+			t.Errorf("%s: not resolved under VkFormat", tt.name)
+			continue
+		}
+		if got := vd.ValueString(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+const extensionDependsFixture = `<registry>
+<types>
+	<type category="handle"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkSurfaceKHR</name>)</type>
+	<type category="struct" name="VkSurfaceInfo"><member><type>VkSurfaceKHR</type> <name>surface</name></member></type>
+	<type category="struct" name="VkOldSurfaceInfo"><member><type>uint32_t</type> <name>flags</name></member></type>
+	<type category="struct" name="VkDisplayInfo"><member><type>uint32_t</type> <name>display</name></member></type>
+</types>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0"/>
+<feature api="vulkan" name="VK_VERSION_1_1" number="1.1" depends="VK_VERSION_1_0+VK_KHR_display">
+	<require><type name="VkSurfaceInfo"/></require>
+</feature>
+<extensions>
+	<extension name="VK_KHR_surface" number="1" supported="vulkan">
+		<require>
+			<enum value="25" name="VK_KHR_SURFACE_SPEC_VERSION"/>
+			<type name="VkSurfaceKHR"/>
+			<type name="VkOldSurfaceInfo"/>
+		</require>
+		<remove><type name="VkOldSurfaceInfo"/></remove>
+	</extension>
+	<extension name="VK_KHR_display" number="3" supported="vulkan" depends="VK_KHR_surface">
+		<require><type name="VkDisplayInfo"/></require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestFeatureDependsOnExtension(t *testing.T) {
+	_, _, f := readResolvedFeature(t, extensionDependsFixture, "VK_VERSION_1_1")
+
+	tests := []struct {
+		name       string
+		wantOrigin string // "" if the name must not be resolved
+	}{
+		{"VkSurfaceInfo", "VK_VERSION_1_1"},
+		{"VkDisplayInfo", "VK_KHR_display"},
+		// Read through VK_KHR_display's own depends attribute
+		{"VkSurfaceKHR", "VK_KHR_surface"},
+		// Removed by the extension's remove block
+		{"VkOldSurfaceInfo", ""},
+	}
+	for _, tt := range tests {
+		resolved := f.ResolvedTypes[tt.name] != nil
+		if resolved != (tt.wantOrigin != "") {
+			t.Errorf("%s: resolved is %v, want %v", tt.name, resolved, tt.wantOrigin != "")
+		}
+		if resolved && f.OriginOf(tt.name) != tt.wantOrigin {
+			t.Errorf("%s: introduced by %q, want %s", tt.name, f.OriginOf(tt.name), tt.wantOrigin)
+		}
+	}
+	if _, found := f.ResolvedValues[""]["VK_KHR_SURFACE_SPEC_VERSION"]; !found {
+		t.Errorf("extension constant VK_KHR_SURFACE_SPEC_VERSION was not read")
+	}
+}
+
+const requireBlockFixture = `<registry>
+<types>
+	<type category="enum" name="VkFormat"/>
+	<type category="struct" name="VkBlockInfoKHR"><member><type>uint32_t</type> <name>info</name></member></type>
+	<type category="struct" name="VkBlockSCInfoKHR"><member><type>uint32_t</type> <name>sc</name></member></type>
+</types>
+<enums name="VkFormat" type="enum">
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+</enums>
+<feature api="vulkan,vulkansc" name="VK_VERSION_1_0" number="1.0">
+	<require><type name="VkFormat"/></require>
+</feature>
+<extensions>
+	<extension name="VK_KHR_blocks" number="3" supported="vulkan,vulkansc">
+		<require comment="Nothing but a comment"/>
+		<require></require>
+		<require>
+			<comment>A comment child</comment>
+			<!-- an XML comment -->
+			<type name="VkBlockInfoKHR"/>
+			<feature name="blocks" struct="VkBlockInfoKHR"/>
+		</require>
+		<require api="vulkansc">
+			<type name="VkBlockSCInfoKHR"/>
+		</require>
+		<require api="vulkan">
+			<enum offset="0" extends="VkFormat" name="VK_FORMAT_BLOCK_KHR"/>
+		</require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestRequireBlocks(t *testing.T) {
+	tests := []struct {
+		api       string
+		wantTypes string // sorted Vk types resolved for VK_KHR_blocks
+		wantValue bool   // whether VK_FORMAT_BLOCK_KHR is resolved
+	}{
+		{"vulkan", "VkBlockInfoKHR,VkFormat", true},
+		{"vulkansc", "VkBlockInfoKHR,VkBlockSCInfoKHR", false},
+	}
+	for _, tt := range tests {
+		_, _, f := readResolvedFeatureFor(t, requireBlockFixture, "VK_KHR_blocks", NewFilter(tt.api))
+		if got := resolvedVkNames(f); got != tt.wantTypes {
+			t.Errorf("%s: got types %s, want %s", tt.api, got, tt.wantTypes)
+		}
+		if got := f.ResolvedValues["VkFormat"]["VK_FORMAT_BLOCK_KHR"] != nil; got != tt.wantValue {
+			t.Errorf("%s: VK_FORMAT_BLOCK_KHR resolved is %v, want %v", tt.api, got, tt.wantValue)
+		}
+		if names := f.UnresolvedNames(); len(names) != 0 {
+			t.Errorf("%s: unresolved names %v", tt.api, names)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package feat
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
 	"github.com/bbredesen/vk-gen/def"
+	"github.com/sirupsen/logrus"
 )
 
 type Feature struct {
@@ -13,18 +16,76 @@ type Feature struct {
 	version              string
 
 	requireTypeNames, requireValueNames map[string]bool
+	removeTypeNames, removeValueNames   map[string]bool
 	ResolvedTypes                       def.TypeRegistry
 	ResolvedValues                      map[string]def.ValueRegistry
+
+	unresolvedNames    map[string]bool
+	requiredExtensions map[string]string // extension name to its type attribute, "instance" or "device"
+	introducedBy       map[string]string // required type or value name to the feature or extension that first required it
+
+	deprecatedBy string
+	deprecations map[string]deprecation
+
+	includedFeatures    map[string]bool // names of the features and extensions read into f
+	conditionalRequires []conditionalRequire
+
+	logger Logger // from the Filter f was read with, may be nil
+}
+
+// conditionalRequire holds the names from a <require depends="..."> block, which are only required if the depends
+// expression is satisfied by the features and extensions being generated together
+type conditionalRequire struct {
+	depends               *dependsExpr
+	origin                string
+	typeNames, valueNames map[string]bool
+}
+
+// deprecation records why a type is deprecated. If aliasesOnly is set, the note only applies when the type is an alias,
+// which is the case for the names left behind when an extension is promoted to core.
+type deprecation struct {
+	note        string
+	aliasesOnly bool
 }
 
 func NewFeature() *Feature {
 	return &Feature{
-		requireTypeNames:  make(map[string]bool),
-		requireValueNames: make(map[string]bool),
-		ResolvedTypes:     make(def.TypeRegistry),
-		ResolvedValues:    make(map[string]def.ValueRegistry),
+		requireTypeNames:   make(map[string]bool),
+		requireValueNames:  make(map[string]bool),
+		removeTypeNames:    make(map[string]bool),
+		removeValueNames:   make(map[string]bool),
+		unresolvedNames:    make(map[string]bool),
+		requiredExtensions: make(map[string]string),
+		introducedBy:       make(map[string]string),
+		deprecations:       make(map[string]deprecation),
+		includedFeatures:   make(map[string]bool),
+		ResolvedTypes:      make(def.TypeRegistry),
+		ResolvedValues:     make(map[string]def.ValueRegistry),
+	}
+
+}
+
+// NewFeatureFromNames returns a feature that requires only the named types, commands and values, without reading any
+// <feature> or <extension> node. Their dependencies are still pulled in by Resolve. Names that are in neither registry
+// are reported by UnresolvedNames after Resolve.
+func NewFeatureFromNames(names []string, tr def.TypeRegistry, vr def.ValueRegistry) *Feature {
+	rval := NewFeature()
+	for _, n := range names {
+		if _, isValue := vr[n]; isValue && tr[n] == nil {
+			rval.requireValueNames[n] = true
+		} else {
+			rval.requireTypeNames[n] = true
+		}
 	}
+	return rval
+}
 
+// log returns the feature's Logger, or one that discards everything
+func (f *Feature) log() Logger {
+	if f.logger == nil {
+		return nopLogger{}
+	}
+	return f.logger
 }
 
 func (f *Feature) MergeIncludeSet(is *def.IncludeSet) {
@@ -54,30 +115,196 @@ func (f *Feature) MergeIncludeSet(is *def.IncludeSet) {
 }
 
 func (f *Feature) Resolve(tr def.TypeRegistry, vr def.ValueRegistry) {
-	for k := range f.requireTypeNames {
+	f.applyConditionalRequires()
+
+	// Each type's Resolve produces an independent IncludeSet, which are merged after the walk is complete. The walk
+	// itself is not run concurrently, see "Parallel Feature.Resolve (declined)" in DesignNotes.md.
+	// Names are walked in sorted order so that a dependency shared by several required types is always credited to the
+	// same origin, see claimResolved
+	sets := make([]*def.IncludeSet, 0, len(f.requireTypeNames))
+	for _, k := range sortedKeys(f.requireTypeNames) {
 		if tr[k] == nil {
-			continue // Skip types not found in registry
+			// Skip types not found in registry, but record them so the caller can report the incomplete output
+			f.unresolvedNames[k] = true
+			f.log().Skipped(k, "not in the type registry")
+			continue
 		}
-		f.MergeIncludeSet(tr[k].Resolve(tr, vr))
+		is := tr[k].Resolve(tr, vr)
+		f.log().Merged(k, len(is.ResolvedTypes), len(is.ResolvedValues))
+		f.claimResolved(f.introducedBy[k], is)
+		sets = append(sets, is)
+	}
+	for _, is := range sets {
+		f.MergeIncludeSet(is)
 	}
 
-	for k, v := range vr {
-		if v.IsCore() && f.ResolvedTypes[vr[k].UnderlyingTypeName()] != nil {
-			f.requireValueNames[k] = true
+	// Resolving a value also resolves its type, e.g. requiring VK_FORMAT_R8_UNORM pulls in VkFormat, and a newly
+	// resolved type brings its own core values with it. Repeat until a pass resolves no new types.
+	doneValues := make(map[string]bool)
+	for {
+		for k, v := range vr {
+			if v.IsCore() && f.ResolvedTypes[vr[k].UnderlyingTypeName()] != nil {
+				f.requireValueNames[k] = true
+				// Core values are generated along with their type
+				if _, found := f.introducedBy[k]; !found {
+					f.introducedBy[k] = f.introducedBy[v.UnderlyingTypeName()]
+				}
+			}
+		}
+
+		typeCount := len(f.ResolvedTypes)
+		for _, k := range sortedKeys(f.requireValueNames) {
+			if doneValues[k] {
+				continue
+			}
+			doneValues[k] = true
+
+			val := vr[k]
+			if val == nil {
+				f.unresolvedNames[k] = true
+				f.log().Skipped(k, "not in the value registry")
+				continue
+			}
+			is := val.Resolve(tr, vr)
+			f.log().Merged(k, len(is.ResolvedTypes), len(is.ResolvedValues))
+			f.claimResolved(f.introducedBy[k], is)
+			f.MergeIncludeSet(is)
+
+			resVals, found := f.ResolvedValues[val.UnderlyingTypeName()]
+			if !found {
+				f.ResolvedValues[val.UnderlyingTypeName()] = make(def.ValueRegistry)
+				resVals = f.ResolvedValues[val.UnderlyingTypeName()]
+			}
+			resVals[val.RegistryName()] = val
+		}
+
+		if len(f.ResolvedTypes) == typeCount {
+			break
 		}
 	}
 
-	for k := range f.requireValueNames {
-		val := vr[k]
-		f.MergeIncludeSet(val.Resolve(tr, vr))
+	f.applyDeprecations()
 
-		resVals, found := f.ResolvedValues[val.UnderlyingTypeName()]
-		if !found {
-			f.ResolvedValues[val.UnderlyingTypeName()] = make(def.ValueRegistry)
-			resVals = f.ResolvedValues[val.UnderlyingTypeName()]
+	// Removed names may have been pulled back in as a dependency of some other type, so strip them again after
+	// resolution
+	f.stripRemovedResolved()
+}
+
+// claimResolved credits the types and values resolved as dependencies of a required name to origin, unless they
+// already have an origin of their own
+func (f *Feature) claimResolved(origin string, is *def.IncludeSet) {
+	if origin == "" {
+		return
+	}
+	for k := range is.ResolvedTypes {
+		if _, found := f.introducedBy[k]; !found {
+			f.introducedBy[k] = origin
+		}
+	}
+	for k := range is.ResolvedValues {
+		if _, found := f.introducedBy[k]; !found {
+			f.introducedBy[k] = origin
+		}
+	}
+}
+
+// OriginOf returns the name of the feature or extension that introduced a type or value: the first one to require it,
+// or, for a name only pulled in as a dependency, the origin of the name that depends on it. It returns an empty string
+// for names that f does not have an origin for.
+func (f *Feature) OriginOf(name string) string { return f.introducedBy[name] }
+
+func sortedKeys(m map[string]bool) []string {
+	rval := make([]string, 0, len(m))
+	for k := range m {
+		rval = append(rval, k)
+	}
+	sort.Strings(rval)
+	return rval
+}
+
+func (f *Feature) stripRemovedResolved() {
+	for _, k := range sortedKeys(f.removeTypeNames) {
+		if f.ResolvedTypes[k] != nil {
+			f.log().Skipped(k, "removed by a <remove> block")
+		}
+		delete(f.ResolvedTypes, k)
+		delete(f.ResolvedValues, k)
+	}
+	for k := range f.removeValueNames {
+		for _, resVals := range f.ResolvedValues {
+			delete(resVals, k)
+		}
+	}
+}
+
+// UnresolvedNames returns the sorted names of required types and values that were not found in the registry during
+// Resolve. A non-empty result means the generated output will be missing those symbols.
+func (f *Feature) UnresolvedNames() []string {
+	rval := make([]string, 0, len(f.unresolvedNames))
+	for k := range f.unresolvedNames {
+		rval = append(rval, k)
+	}
+	sort.Strings(rval)
+	return rval
+}
+
+// RequiredExtensions returns the sorted names of the extensions merged into this feature, either directly or through
+// the depends attribute of a merged feature.
+func (f *Feature) RequiredExtensions() []string { return f.requiredExtensionsOfType("") }
+
+// RequiredInstanceExtensions returns the sorted names of the merged extensions that must be enabled when creating the
+// VkInstance
+func (f *Feature) RequiredInstanceExtensions() []string {
+	return f.requiredExtensionsOfType("instance")
+}
+
+// RequiredDeviceExtensions returns the sorted names of the merged extensions that must be enabled when creating the
+// VkDevice
+func (f *Feature) RequiredDeviceExtensions() []string { return f.requiredExtensionsOfType("device") }
+
+// requiredExtensionsOfType returns the extensions with the given type attribute, or all extensions if extType is empty
+func (f *Feature) requiredExtensionsOfType(extType string) []string {
+	rval := make([]string, 0, len(f.requiredExtensions))
+	for k, v := range f.requiredExtensions {
+		if extType == "" || v == extType {
+			rval = append(rval, k)
 		}
-		resVals[val.RegistryName()] = val
 	}
+	sort.Strings(rval)
+	return rval
+}
+
+// SortedTypes returns the resolved types sorted by registry name
+func (f *Feature) SortedTypes() []def.TypeDefiner { return f.ResolvedTypes.Sorted() }
+
+// SortedValueTypeNames returns the keys of ResolvedValues, i.e. the underlying type names of the resolved values, in
+// sorted order
+func (f *Feature) SortedValueTypeNames() []string {
+	rval := make([]string, 0, len(f.ResolvedValues))
+	for k := range f.ResolvedValues {
+		rval = append(rval, k)
+	}
+	sort.Strings(rval)
+	return rval
+}
+
+// Stats counts the resolved types of each category. Categories without any types are left out; range over the
+// categories from def.CatNone to def.CatMaximum for a deterministic order.
+func (f *Feature) Stats() map[def.TypeCategory]int {
+	rval := make(map[def.TypeCategory]int)
+	for _, td := range f.ResolvedTypes {
+		rval[td.Category()]++
+	}
+	return rval
+}
+
+// ValueCount returns the number of resolved values, of all types
+func (f *Feature) ValueCount() int {
+	n := 0
+	for _, vals := range f.ResolvedValues {
+		n += len(vals)
+	}
+	return n
 }
 
 func (f *Feature) FilterByCategory() map[def.TypeCategory]*Feature {
@@ -125,27 +352,35 @@ func (f *Feature) FilterByCategory() map[def.TypeCategory]*Feature {
 	return rval
 }
 
-func ReadFeatureFromXML(featureNode *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry) *Feature {
+// ReadFeatureFromXML reads a feature and, recursively, the features it depends on. Nodes are selected according to
+// filter, which may be nil to read everything. The document is indexed on each call, so use Registry.ReadFeature or
+// ReadFeaturesInRange to read several features.
+func ReadFeatureFromXML(featureNode *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter) *Feature {
 	if featureNode == nil {
 		return nil
 	}
 
-	// Find the root document by traversing up from featureNode
-	root := featureNode
-	for root.Parent != nil {
-		root = root.Parent
-	}
-
 	visited := make(map[string]bool)
-	return readFeatureFromXMLWithDeps(featureNode, root, tr, vr, visited)
+	return readFeatureFromXMLWithDeps(featureNode, newNodeIndex(documentRoot(featureNode)), tr, vr, filter, visited)
 }
 
-func readFeatureFromXMLWithDeps(featureNode, root *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry, visited map[string]bool) *Feature {
-	if featureNode == nil {
+func readFeatureFromXMLWithDeps(featureNode *xmlquery.Node, index nodeIndex, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter, visited map[string]bool) *Feature {
+	if featureNode == nil || !filter.apiIncluded(featureNode) {
 		return nil
 	}
 
 	featureName := featureNode.SelectAttr("name")
+	if filter.IsExcluded(featureName) {
+		filter.log().Skipped(featureName, "excluded by the filter")
+		return nil
+	}
+	if !filter.IsSupported(featureNode) {
+		logrus.WithField("extension", featureName).
+			WithField("supported", featureNode.SelectAttr("supported")).
+			Warn("dependency is not supported for the target API and will not be included")
+		filter.log().Skipped(featureName, "not supported for the target API")
+		return nil
+	}
 
 	// Avoid infinite loops from circular dependencies
 	if visited[featureName] {
@@ -157,63 +392,217 @@ func readFeatureFromXMLWithDeps(featureNode, root *xmlquery.Node, tr def.TypeReg
 	rval.apiName = featureNode.SelectAttr("api")
 	rval.featureName = featureName
 	rval.version = featureNode.SelectAttr("number")
+	rval.deprecatedBy = featureNode.SelectAttr("deprecatedby")
+	rval.includedFeatures[featureName] = true
+	if filter != nil {
+		rval.logger = filter.Logger
+	}
+
+	// <extension> nodes share the require/remove/depends structure of <feature>, but have to be enabled at instance or
+	// device creation
+	extNum := 0
+	if featureNode.Data == "extension" {
+		rval.requiredExtensions[featureName] = featureNode.SelectAttr("type")
+
+		var err error
+		if extNum, err = strconv.Atoi(featureNode.SelectAttr("number")); err != nil {
+			logrus.WithField("extension", featureName).
+				WithField("number", featureNode.SelectAttr("number")).
+				WithError(err).
+				Error("could not convert extension number, enum offsets will be incorrect")
+		}
+	}
+
+	// Make sure the packed version define for this feature (e.g. VK_API_VERSION_1_3 for VK_VERSION_1_3) is generated,
+	// even if the feature's require blocks do not list it
+	if versionDefine := strings.Replace(featureName, "_VERSION_", "_API_VERSION_", 1); versionDefine != featureName && tr[versionDefine] != nil {
+		rval.requireTypeNames[versionDefine] = true
+	}
 
 	// Process the "depends" attribute - this is crucial for Vulkan 1.4+
-	// Dependencies can be comma-separated, e.g., "VK_VERSION_1_0,VK_GRAPHICS_VERSION_1_1"
+	// Dependencies are boolean expressions, e.g., "VK_VERSION_1_0,VK_GRAPHICS_VERSION_1_1" or
+	// "(VK_VERSION_1_1,VK_KHR_get_physical_device_properties2)+VK_KHR_maintenance3"
 	depends := featureNode.SelectAttr("depends")
 	if depends != "" {
-		depNames := strings.Split(depends, ",")
-		for _, depName := range depNames {
-			depName = strings.TrimSpace(depName)
-			if depName == "" {
-				continue
-			}
-
-			// Find the dependent feature node
-			xpath := fmt.Sprintf("//feature[@name='%s']", depName)
-			depNode := xmlquery.FindOne(root, xpath)
-			if depNode != nil {
-				depFeature := readFeatureFromXMLWithDeps(depNode, root, tr, vr, visited)
-				if depFeature != nil {
-					rval.MergeWith(depFeature)
-				}
-			}
+		expr, err := parseDepends(depends)
+		if err != nil {
+			logrus.WithField("feature", featureName).
+				WithField("depends", depends).
+				WithField("error", err).
+				Warn("could not parse depends expression, dependencies will not be included")
+		} else {
+			rval.MergeWith(readDependsFromXML(expr, index, tr, vr, filter, visited))
 		}
 	}
 
 	for _, reqNode := range xmlquery.Find(featureNode, "/require") {
-		for _, typeNode := range xmlquery.Find(reqNode, "/type") {
-			rval.requireTypeNames[typeNode.SelectAttr("name")] = true
+		typeNames, enumNodes := readBlockEntries(reqNode, filter)
+		requireTypes, requireValues := rval.requireTargets(reqNode)
+		for _, n := range typeNames {
+			requireTypes[n] = true
 		}
 
-		for _, cmdNode := range xmlquery.Find(reqNode, "/command") {
-			rval.requireTypeNames[cmdNode.SelectAttr("name")] = true
-		}
-
-		for _, enumNode := range xmlquery.Find(reqNode, "/enum") {
+		for _, enumNode := range enumNodes {
 			extendsTypeName := enumNode.SelectAttr("extends")
 
 			if extendsTypeName != "" {
 				// Defines a new enum value, which extends a global type
 				td := tr[extendsTypeName]
+				var vd def.ValueDefiner
 				if enumNode.SelectAttr("bitpos") != "" {
-					vd := def.NewBitmaskValueFromXML(td, enumNode)
-					vr[vd.RegistryName()] = vd
+					vd = def.NewBitmaskValueFromXML(td, enumNode)
 				} else {
-					vd := def.NewEnumValueFromXML(td, enumNode)
-					vr[vd.RegistryName()] = vd
+					vd = def.NewEnumValueFromXML(td, enumNode)
 				}
+				// Offsets within an extension are relative to its own number, unless the enum specifies extnumber
+				vd.SetExtensionNumber(extNum)
+				registerExtendedValue(vr, vd, featureName)
+			} else if extNum != 0 && enumNode.SelectAttr("value") != "" {
+				// Extension name and spec version constants
+				registerExtendedValue(vr, def.NewUntypedEnumValueFromXML(enumNode), featureName)
 			}
 
-			rval.requireValueNames[enumNode.SelectAttr("name")] = true
+			requireValues[enumNode.SelectAttr("name")] = true
 		}
 	}
 
+	rval.claimRequired()
+	rval.readDeprecationsFromXML(featureNode, filter)
+
+	// Removals are applied after all dependencies and requires have been gathered
+	for _, remNode := range xmlquery.Find(featureNode, "/remove") {
+		typeNames, enumNodes := readBlockEntries(remNode, filter)
+		for _, n := range typeNames {
+			rval.removeTypeNames[n] = true
+		}
+		for _, enumNode := range enumNodes {
+			rval.removeValueNames[enumNode.SelectAttr("name")] = true
+		}
+	}
+	rval.applyRemovals()
+
 	return rval
 }
 
+// readBlockEntries returns the names of the <type> and <command> children of a <require> or <remove> block, and its
+// <enum> children, for the filter's API. A block for another API gives nothing. Only element children are read, so
+// <comment> children, XML comments and blocks holding nothing but a comment attribute are skipped, as are the
+// <feature> children that name the device features an extension needs.
+func readBlockEntries(blockNode *xmlquery.Node, filter *Filter) (typeNames []string, enumNodes []*xmlquery.Node) {
+	if !filter.apiIncluded(blockNode) {
+		return nil, nil
+	}
+
+	for n := blockNode.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type != xmlquery.ElementNode || !filter.apiIncluded(n) {
+			continue
+		}
+		switch n.Data {
+		case "type", "command":
+			typeNames = append(typeNames, n.SelectAttr("name"))
+		case "enum":
+			enumNodes = append(enumNodes, n)
+		}
+	}
+	return typeNames, enumNodes
+}
+
+// readDependsFromXML walks a parsed depends expression and returns the merged requirements of its operands. For
+// OR groups, only the first satisfiable alternative is read. For AND groups, every operand is merged.
+func readDependsFromXML(expr *dependsExpr, index nodeIndex, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter, visited map[string]bool) *Feature {
+	if expr == nil {
+		return nil
+	}
+
+	switch expr.op {
+	case dependsName:
+		if filter.IsExcluded(expr.name) {
+			logrus.WithField("dependency", expr.name).
+				Warn("required dependency is excluded and will not be included")
+			return nil
+		}
+		depNode := index[expr.name]
+		if depNode == nil {
+			filter.log().Skipped(expr.name, "dependency is not in the registry")
+			return nil
+		}
+		return readFeatureFromXMLWithDeps(depNode, index, tr, vr, filter, visited)
+
+	case dependsOr:
+		for _, alt := range expr.operands {
+			if dependsSatisfiable(alt, index, filter) {
+				return readDependsFromXML(alt, index, tr, vr, filter, visited)
+			}
+		}
+		logrus.WithField("depends", expr.String()).
+			Warn("no alternative in dependency expression can be satisfied")
+		return nil
+
+	case dependsAnd:
+		rval := NewFeature()
+		for _, o := range expr.operands {
+			rval.MergeWith(readDependsFromXML(o, index, tr, vr, filter, visited))
+		}
+		return rval
+	}
+
+	return nil
+}
+
+// dependsSatisfiable reports whether every name required by the expression can be found in the registry and is not
+// excluded by the filter.
+func dependsSatisfiable(expr *dependsExpr, index nodeIndex, filter *Filter) bool {
+	switch expr.op {
+	case dependsName:
+		if filter.IsExcluded(expr.name) {
+			return false
+		}
+		// Depends expressions mix core versions and extensions freely, the index holds both
+		n := index[expr.name]
+		return n != nil && filter.IsSupported(n)
+	case dependsOr:
+		for _, o := range expr.operands {
+			if dependsSatisfiable(o, index, filter) {
+				return true
+			}
+		}
+		return false
+	case dependsAnd:
+		for _, o := range expr.operands {
+			if !dependsSatisfiable(o, index, filter) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// registerExtendedValue adds a value defined by an <enum extends=...> node to the registry. The same value is
+// frequently defined by more than one feature or extension (e.g. when an extension is promoted to core). If an
+// existing definition already has a computed value, it is kept, and a warning is logged when the two definitions
+// disagree.
+func registerExtendedValue(vr def.ValueRegistry, vd def.ValueDefiner, sourceName string) {
+	existing, found := vr[vd.RegistryName()]
+	if !found || existing.IsAlias() || existing.ValueString() == "" {
+		vr[vd.RegistryName()] = vd
+		return
+	}
+
+	if !vd.IsAlias() && vd.ValueString() != existing.ValueString() {
+		logrus.WithField("registry name", vd.RegistryName()).
+			WithField("source", sourceName).
+			WithField("existing value", existing.ValueString()).
+			WithField("new value", vd.ValueString()).
+			Warn("enum value is defined more than once with different values, keeping the existing definition")
+	}
+}
+
 func (f *Feature) Name() string { return f.featureName }
 
+// Version returns the number attribute of the feature, e.g. "1.3"
+func (f *Feature) Version() string { return f.version }
+
 func (f *Feature) MergeWith(g *Feature) {
 	if g == nil {
 		return
@@ -224,4 +613,190 @@ func (f *Feature) MergeWith(g *Feature) {
 	for k, v := range g.requireValueNames {
 		f.requireValueNames[k] = v
 	}
+	for k, v := range g.removeTypeNames {
+		f.removeTypeNames[k] = v
+	}
+	for k, v := range g.removeValueNames {
+		f.removeValueNames[k] = v
+	}
+	for k, v := range g.deprecations {
+		f.deprecations[k] = v
+	}
+	for k, v := range g.requiredExtensions {
+		f.requiredExtensions[k] = v
+	}
+	for k, v := range g.introducedBy {
+		if _, found := f.introducedBy[k]; !found {
+			f.introducedBy[k] = v
+		}
+	}
+	for k := range g.includedFeatures {
+		f.includedFeatures[k] = true
+	}
+	f.conditionalRequires = append(f.conditionalRequires, g.conditionalRequires...)
+	if f.logger == nil {
+		f.logger = g.logger
+	}
+	f.applyRemovals()
+}
+
+// IncludeFeaturesFrom counts the features and extensions read into base as generated along with f, when checking the
+// depends attribute of f's require blocks. Platform features are resolved apart from the core feature they build on,
+// so they need this to see the core versions and extensions.
+func (f *Feature) IncludeFeaturesFrom(base *Feature) {
+	for k := range base.includedFeatures {
+		f.includedFeatures[k] = true
+	}
+}
+
+// requireTargets returns the maps that the names of a require block are added to. These are f's own required names,
+// unless the block has a depends attribute, in which case they are held back until Resolve, see
+// applyConditionalRequires.
+func (f *Feature) requireTargets(reqNode *xmlquery.Node) (typeNames, valueNames map[string]bool) {
+	depends := reqNode.SelectAttr("depends")
+	if depends == "" {
+		return f.requireTypeNames, f.requireValueNames
+	}
+
+	expr, err := parseDepends(depends)
+	if err != nil {
+		logrus.WithField("feature", f.featureName).
+			WithField("depends", depends).
+			WithField("error", err).
+			Warn("could not parse depends expression of require block, requiring its names unconditionally")
+		return f.requireTypeNames, f.requireValueNames
+	}
+
+	cr := conditionalRequire{
+		depends:    expr,
+		origin:     f.featureName,
+		typeNames:  make(map[string]bool),
+		valueNames: make(map[string]bool),
+	}
+	f.conditionalRequires = append(f.conditionalRequires, cr)
+	return cr.typeNames, cr.valueNames
+}
+
+// applyConditionalRequires adds the names of each conditional require block whose depends expression is satisfied by
+// the features and extensions merged into f. Blocks that are not satisfied are kept, in case f is merged into a larger
+// feature later.
+func (f *Feature) applyConditionalRequires() {
+	var pending []conditionalRequire
+	for _, cr := range f.conditionalRequires {
+		if !cr.depends.satisfiedBy(f.includedFeatures) {
+			pending = append(pending, cr)
+			continue
+		}
+		for _, names := range []map[string]bool{cr.typeNames, cr.valueNames} {
+			for _, k := range sortedKeys(names) {
+				if _, found := f.introducedBy[k]; !found {
+					f.introducedBy[k] = cr.origin
+					f.log().Required(k, cr.origin)
+				}
+			}
+		}
+		for k := range cr.typeNames {
+			f.requireTypeNames[k] = true
+		}
+		for k := range cr.valueNames {
+			f.requireValueNames[k] = true
+		}
+	}
+	f.conditionalRequires = pending
+	f.applyRemovals()
+}
+
+// MergeResolved merges g into f like MergeWith, and also merges the types and values that g has already resolved, so
+// two independently resolved features can be combined without calling Resolve again. Both maps are keyed by registry
+// name, so a type or value resolved by both features is only kept once.
+func (f *Feature) MergeResolved(g *Feature) {
+	if g == nil {
+		return
+	}
+	f.MergeWith(g)
+
+	for k, v := range g.ResolvedTypes {
+		f.ResolvedTypes[k] = v
+	}
+	for typeName, vals := range g.ResolvedValues {
+		resVals, found := f.ResolvedValues[typeName]
+		if !found {
+			resVals = make(def.ValueRegistry)
+			f.ResolvedValues[typeName] = resVals
+		}
+		for k, v := range vals {
+			resVals[k] = v
+		}
+	}
+	for k := range g.unresolvedNames {
+		f.unresolvedNames[k] = true
+	}
+
+	f.stripRemovedResolved()
+}
+
+// claimRequired records f as the origin of each required name that was not already introduced by one of its
+// dependencies
+func (f *Feature) claimRequired() {
+	for _, names := range []map[string]bool{f.requireTypeNames, f.requireValueNames} {
+		for _, k := range sortedKeys(names) {
+			if _, found := f.introducedBy[k]; !found {
+				f.introducedBy[k] = f.featureName
+				f.log().Required(k, f.featureName)
+			}
+		}
+	}
+}
+
+// readDeprecationsFromXML records deprecations from the deprecatedby attribute, which deprecates everything the
+// feature requires, and from <deprecate> blocks, which list individual types and commands.
+func (f *Feature) readDeprecationsFromXML(node *xmlquery.Node, filter *Filter) {
+	// Only the names required directly by this node are deprecated, not those merged in from its dependencies
+	if f.deprecatedBy != "" {
+		for _, n := range xmlquery.Find(node, "/require/type|/require/command") {
+			f.deprecations[n.SelectAttr("name")] = deprecation{note: fmt.Sprintf("%s is deprecated by %s.", f.featureName, f.deprecatedBy)}
+		}
+	}
+
+	for _, depNode := range xmlquery.Find(node, "/deprecate") {
+		if !filter.apiIncluded(depNode) {
+			continue
+		}
+
+		note := fmt.Sprintf("deprecated in %s.", f.featureName)
+		if link := depNode.SelectAttr("explanationlink"); link != "" {
+			note = fmt.Sprintf("%s See %s", note, link)
+		}
+
+		for _, n := range xmlquery.Find(depNode, "/type|/command") {
+			f.deprecations[n.SelectAttr("name")] = deprecation{note: note}
+		}
+	}
+}
+
+// applyDeprecations flags each resolved type that has a recorded deprecation
+func (f *Feature) applyDeprecations() {
+	for k, d := range f.deprecations {
+		td, found := f.ResolvedTypes[k]
+		if !found {
+			continue
+		}
+		if d.aliasesOnly && !td.IsAlias() {
+			continue
+		}
+		if dep, ok := td.(def.Deprecator); ok {
+			dep.SetDeprecated(d.note)
+		}
+	}
+}
+
+// applyRemovals subtracts every removed name from the required names. A symbol removed by any merged feature stays
+// removed, even when an earlier feature required it.
+func (f *Feature) applyRemovals() {
+	for k := range f.removeTypeNames {
+		delete(f.requireTypeNames, k)
+	}
+	for k := range f.removeValueNames {
+		delete(f.requireValueNames, k)
+	}
 }
@@ -0,0 +1,77 @@
+package feat
+
+import (
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// FeatureInfo describes a <feature> or <extension> node without reading its requirements.
+type FeatureInfo struct {
+	Name string
+	// IsExtension is false for core versions and true for extensions
+	IsExtension bool
+	// Version is the API version of a feature (e.g. "1.3") or the spec version of an extension, from its
+	// _SPEC_VERSION enum. It may be empty.
+	Version string
+	// Number is the extension number, which enum offsets are based on. Empty for features.
+	Number string
+	// API lists the APIs a feature applies to; Supported lists the APIs an extension is supported for, or "disabled"
+	API, Supported []string
+	// Platform is the platform name of an extension, empty for core
+	Platform string
+	// Type is "instance" or "device" for extensions
+	Type string
+
+	Depends, PromotedTo, DeprecatedBy string
+	Provisional                       bool
+}
+
+// ListFeatures returns every feature and then every extension in the registry, in document order.
+func ListFeatures(root *xmlquery.Node) []FeatureInfo {
+	var rval []FeatureInfo
+
+	for _, n := range xmlquery.Find(root, "//feature") {
+		rval = append(rval, FeatureInfo{
+			Name:    n.SelectAttr("name"),
+			Version: n.SelectAttr("number"),
+			API:     splitList(n.SelectAttr("api")),
+			Depends: n.SelectAttr("depends"),
+		})
+	}
+
+	for _, n := range xmlquery.Find(root, "//extensions/extension") {
+		info := FeatureInfo{
+			Name:         n.SelectAttr("name"),
+			IsExtension:  true,
+			Number:       n.SelectAttr("number"),
+			Supported:    splitList(n.SelectAttr("supported")),
+			Platform:     n.SelectAttr("platform"),
+			Type:         n.SelectAttr("type"),
+			Depends:      n.SelectAttr("depends"),
+			PromotedTo:   n.SelectAttr("promotedto"),
+			DeprecatedBy: n.SelectAttr("deprecatedby"),
+			Provisional:  n.SelectAttr("provisional") == "true",
+		}
+		for _, e := range xmlquery.Find(n, "/require/enum[@value]") {
+			if strings.HasSuffix(e.SelectAttr("name"), "_SPEC_VERSION") {
+				info.Version = e.SelectAttr("value")
+				break
+			}
+		}
+		rval = append(rval, info)
+	}
+
+	return rval
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	rval := strings.Split(s, ",")
+	for i := range rval {
+		rval[i] = strings.TrimSpace(rval[i])
+	}
+	return rval
+}
@@ -0,0 +1,306 @@
+package feat
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const apiFixture = `<registry>
+<types>
+	<type category="enum" name="VkFormat"/>
+	<type category="struct" name="VkCore"><member><type>uint32_t</type> <name>core</name></member></type>
+	<type category="struct" name="VkVulkanOnly"><member><type>uint32_t</type> <name>vulkan</name></member></type>
+	<type category="struct" name="VkSC"><member><type>uint32_t</type> <name>sc</name></member></type>
+	<type category="struct" name="VkSCFeature"><member><type>uint32_t</type> <name>scFeature</name></member></type>
+</types>
+<enums name="VkFormat" type="enum">
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+</enums>
+<feature api="vulkan,vulkansc" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkCore"/>
+		<type name="VkFormat"/>
+		<enum api="vulkansc" extends="VkFormat" value="5" name="VK_FORMAT_SC"/>
+	</require>
+	<require api="vulkan"><type name="VkVulkanOnly"/></require>
+	<require api="vulkansc"><type name="VkSC"/></require>
+</feature>
+<feature api="vulkansc" name="VKSC_VERSION_1_0" number="1.0">
+	<require><type name="VkSCFeature"/></require>
+</feature>
+<feature api="vulkan,vulkansc" name="VK_VERSION_1_1" number="1.1" depends="VK_VERSION_1_0+VKSC_VERSION_1_0">
+</feature>
+</registry>`
+
+func TestReadFeatureAPI(t *testing.T) {
+	tests := []struct {
+		api       string
+		wantTypes string
+		wantValue bool // whether the vulkansc only VK_FORMAT_SC is resolved
+	}{
+		{"vulkan", "VkCore,VkFormat,VkVulkanOnly", false},
+		{"vulkansc", "VkCore,VkFormat,VkSC,VkSCFeature", true},
+		// No filter reads every block
+		{"", "VkCore,VkFormat,VkSC,VkSCFeature,VkVulkanOnly", true},
+	}
+	for _, tt := range tests {
+		_, _, f := readResolvedFeatureFor(t, apiFixture, "VK_VERSION_1_1", NewFilter(tt.api))
+		if got := resolvedVkNames(f); got != tt.wantTypes {
+			t.Errorf("%q: got %s, want %s", tt.api, got, tt.wantTypes)
+		}
+		if got := f.ResolvedValues["VkFormat"]["VK_FORMAT_SC"] != nil; got != tt.wantValue {
+			t.Errorf("%q: VK_FORMAT_SC resolved is %v, want %v", tt.api, got, tt.wantValue)
+		}
+	}
+}
+
+const aliasFixture = `<registry>
+<types>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkPhysicalDevice</name>)</type>
+	<type category="struct" name="VkPhysicalDeviceFeatures2">
+		<member><type>uint32_t</type> <name>robustBufferAccess</name></member>
+	</type>
+	<type category="struct" name="VkPhysicalDeviceFeatures2KHR" alias="VkPhysicalDeviceFeatures2"/>
+	<type category="enum" name="VkPointClippingBehavior"/>
+	<type category="enum" name="VkPointClippingBehaviorKHR" alias="VkPointClippingBehavior"/>
+</types>
+<enums name="VkPointClippingBehavior" type="enum">
+	<enum value="0" name="VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES"/>
+	<enum name="VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES_KHR" alias="VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES"/>
+</enums>
+<commands>
+	<command>
+		<proto><type>void</type> <name>vkGetPhysicalDeviceFeatures2</name></proto>
+		<param><type>VkPhysicalDevice</type> <name>physicalDevice</name></param>
+		<param><type>VkPhysicalDeviceFeatures2</type>* <name>pFeatures</name></param>
+	</command>
+	<command name="vkGetPhysicalDeviceFeatures2KHR" alias="vkGetPhysicalDeviceFeatures2"/>
+</commands>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkPhysicalDeviceFeatures2KHR"/>
+		<type name="VkPointClippingBehaviorKHR"/>
+		<command name="vkGetPhysicalDeviceFeatures2KHR"/>
+		<enum name="VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES_KHR"/>
+	</require>
+</feature>
+</registry>`
+
+func TestResolveAliases(t *testing.T) {
+	_, _, f := readResolvedFeature(t, aliasFixture, "VK_VERSION_1_0")
+
+	// Only the aliases are required, the aliased names must be resolved along with them
+	tests := []struct {
+		alias, target, wantDecl string
+	}{
+		{"VkPhysicalDeviceFeatures2KHR", "VkPhysicalDeviceFeatures2", "type PhysicalDeviceFeatures2KHR = PhysicalDeviceFeatures2"},
+		{"VkPointClippingBehaviorKHR", "VkPointClippingBehavior", "type PointClippingBehaviorKHR = PointClippingBehavior"},
+		{"vkGetPhysicalDeviceFeatures2KHR", "vkGetPhysicalDeviceFeatures2", "var GetPhysicalDeviceFeatures2KHR = GetPhysicalDeviceFeatures2"},
+	}
+	for _, tt := range tests {
+		td := f.ResolvedTypes[tt.alias]
+		if td == nil {
+			t.Errorf("%s: not resolved", tt.alias)
+			continue
+		}
+		if !td.IsAlias() {
+			t.Errorf("%s: not an alias", tt.alias)
+		}
+		if f.ResolvedTypes[tt.target] == nil {
+			t.Errorf("%s: aliased type %s not resolved", tt.alias, tt.target)
+		}
+		sb := &strings.Builder{}
+		td.PrintPublicDeclaration(sb)
+		if !strings.Contains(sb.String(), tt.wantDecl) {
+			t.Errorf("%s: want %q in\n%s", tt.alias, tt.wantDecl, sb.String())
+		}
+	}
+
+	// An enum value alias lands in the bucket of the aliased value's type
+	values := f.ResolvedValues["VkPointClippingBehavior"]
+	for _, name := range []string{"VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES", "VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES_KHR"} {
+		if values[name] == nil {
+			t.Errorf("%s: not resolved under VkPointClippingBehavior", name)
+		}
+	}
+	if vd := values["VK_POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES_KHR"]; vd != nil && vd.ValueString() != "POINT_CLIPPING_BEHAVIOR_ALL_CLIP_PLANES" {
+		t.Errorf("value alias: got %s", vd.ValueString())
+	}
+}
+
+const requiredValueFixture = `<registry>
+<types>
+	<type category="enum" name="VkFormat"/>
+	<type category="enum" name="VkObjectType"/>
+	<type category="handle" objtypeenum="VK_OBJECT_TYPE_SAMPLER"><type>VK_DEFINE_NON_DISPATCHABLE_HANDLE</type>(<name>VkSampler</name>)</type>
+	<type category="struct" name="VkSamplerInfo"><member><type>VkSampler</type> <name>sampler</name></member></type>
+	<type category="enum" name="VkInfoKind"/>
+</types>
+<enums name="VkFormat" type="enum">
+	<enum value="0" name="VK_FORMAT_UNDEFINED"/>
+	<enum value="9" name="VK_FORMAT_R8_UNORM"/>
+</enums>
+<enums name="VkObjectType" type="enum">
+	<enum value="21" name="VK_OBJECT_TYPE_SAMPLER"/>
+</enums>
+<enums name="VkInfoKind" type="enum">
+	<enum value="1" name="VK_INFO_KIND_SAMPLER"/>
+</enums>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<enum name="VK_FORMAT_R8_UNORM"/>
+	</require>
+</feature>
+<extensions>
+	<extension name="VK_EXT_sampler_info" number="1" supported="vulkan">
+		<require>
+			<enum offset="0" extends="VkInfoKind" name="VK_INFO_KIND_SAMPLER_EXT"/>
+			<type name="VkSamplerInfo"/>
+		</require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestResolveRequiredValueTypes(t *testing.T) {
+	tests := []struct {
+		feature   string
+		wantTypes []string
+		values    map[string]string // value name to the type it must be resolved under
+	}{
+		// Only a value is required, its enum type comes with it, and the type brings its other core values
+		{"VK_VERSION_1_0", []string{"VkFormat"}, map[string]string{
+			"VK_FORMAT_R8_UNORM":  "VkFormat",
+			"VK_FORMAT_UNDEFINED": "VkFormat",
+		}},
+		// The struct pulls in the handle, whose object type value in turn pulls in VkObjectType
+		{"VK_EXT_sampler_info", []string{"VkInfoKind", "VkSamplerInfo", "VkSampler", "VkObjectType"}, map[string]string{
+			"VK_INFO_KIND_SAMPLER_EXT": "VkInfoKind",
+			"VK_OBJECT_TYPE_SAMPLER":   "VkObjectType",
+		}},
+	}
+	for _, tt := range tests {
+		_, _, f := readResolvedFeature(t, requiredValueFixture, tt.feature)
+		for _, n := range tt.wantTypes {
+			if f.ResolvedTypes[n] == nil {
+				t.Errorf("%s: %s is not resolved", tt.feature, n)
+			}
+		}
+		for v, typeName := range tt.values {
+			if f.ResolvedValues[typeName][v] == nil {
+				t.Errorf("%s: %s is not resolved under %s", tt.feature, v, typeName)
+			}
+		}
+		if names := f.UnresolvedNames(); len(names) != 0 {
+			t.Errorf("%s: unresolved names %v", tt.feature, names)
+		}
+	}
+}
+
+const conditionalRequireFixture = `<registry>
+<types>
+	<type category="struct" name="VkGatedInfoKHR"><member><type>uint32_t</type> <name>gated</name></member></type>
+	<type category="struct" name="VkGatedOtherKHR"><member><type>uint32_t</type> <name>other</name></member></type>
+	<type category="struct" name="VkGatedEitherKHR"><member><type>uint32_t</type> <name>either</name></member></type>
+	<type category="struct" name="VkOtherInfoKHR"><member><type>uint32_t</type> <name>info</name></member></type>
+</types>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+</feature>
+<extensions>
+	<extension name="VK_KHR_gated" number="1" supported="vulkan">
+		<require><type name="VkGatedInfoKHR"/></require>
+		<require depends="VK_KHR_other"><type name="VkGatedOtherKHR"/></require>
+		<require depends="VK_VERSION_1_1,VK_KHR_other"><type name="VkGatedEitherKHR"/></require>
+	</extension>
+	<extension name="VK_KHR_other" number="2" supported="vulkan">
+		<require><type name="VkOtherInfoKHR"/></require>
+	</extension>
+</extensions>
+</registry>`
+
+func TestConditionalRequires(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []string // read and merged in order before resolving
+		want     string   // sorted Vk types resolved
+	}{
+		{"condition not generated", []string{"VK_KHR_gated"}, "VkGatedInfoKHR"},
+		{"condition merged in", []string{"VK_KHR_gated", "VK_KHR_other"},
+			"VkGatedEitherKHR,VkGatedInfoKHR,VkGatedOtherKHR,VkOtherInfoKHR"},
+		{"condition merged in first", []string{"VK_KHR_other", "VK_KHR_gated"},
+			"VkGatedEitherKHR,VkGatedInfoKHR,VkGatedOtherKHR,VkOtherInfoKHR"},
+	}
+	for _, tt := range tests {
+		reg := loadTestRegistry(t, conditionalRequireFixture)
+		f := NewFeature()
+		for _, name := range tt.features {
+			g, err := reg.ReadFeature(name)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			f.MergeWith(g)
+		}
+		f.Resolve(reg.Types, reg.Values)
+
+		var got []string
+		for _, td := range f.SortedTypes() {
+			if strings.HasPrefix(td.RegistryName(), "Vk") {
+				got = append(got, td.RegistryName())
+			}
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkFeatureResolve resolves every core version and supported extension of the real registry, as the command
+// line tool does by default. The registry is read from ../vk.xml, where the tool looks for it when run from the
+// repository root, or from the file named by VK_XML. Resolve marks the definitions it visits, so each iteration reads
+// the registry again, outside of the timer.
+func BenchmarkFeatureResolve(b *testing.B) {
+	path := os.Getenv("VK_XML")
+	if path == "" {
+		path = "../vk.xml"
+	}
+	if _, err := os.Stat(path); err != nil {
+		b.Skipf("registry not found: %v", err)
+	}
+	exceptionsBytes, err := os.ReadFile("../exceptions.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	exceptions := gjson.ParseBytes(exceptionsBytes)
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.FatalLevel)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		reg, err := LoadRegistryFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		reg.ReadDefinitions(exceptions)
+		tr, vr := reg.Types, reg.Values
+
+		f := ReadFeaturesInRange(reg.Root, "", "", tr, vr, reg.Filter)
+		platforms := ReadPlatforms(reg.Root, exceptions)
+		for _, extNode := range xmlquery.Find(reg.Root, "//extensions/extension") {
+			if !reg.Filter.IsSupported(extNode) {
+				continue
+			}
+			ext := ReadExtensionFromXML(extNode, tr, vr, reg.Filter)
+			if p := platforms[ext.PlatformName()]; p != nil {
+				p.IncludeExtension(ext)
+			}
+		}
+		f.MergeWith(platforms[""].GeneratePlatformFeatures())
+		b.StartTimer()
+
+		f.Resolve(tr, vr)
+	}
+}
@@ -0,0 +1,84 @@
+package feat
+
+import (
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// Filter selects which parts of the registry are read into features and extensions. A nil *Filter reads everything.
+type Filter struct {
+	// API is the API variant being generated (e.g. "vulkan" or "vulkansc"). Nodes whose api attribute excludes it are
+	// skipped. An empty string disables API filtering.
+	API string
+
+	// Exclude holds feature and extension names that will not be read, even when another feature depends on them.
+	Exclude map[string]bool
+
+	// Include holds extension names that will be read even though their supported attribute does not list the API,
+	// e.g. provisional or disabled extensions.
+	Include map[string]bool
+
+	// Logger, if set, records why each feature, extension and name is read or skipped, see Logger.
+	Logger Logger
+}
+
+func NewFilter(api string) *Filter {
+	return &Filter{
+		API:     api,
+		Exclude: make(map[string]bool),
+		Include: make(map[string]bool),
+	}
+}
+
+// IsExcluded returns true if the named feature or extension should not be read.
+func (f *Filter) IsExcluded(name string) bool {
+	return f != nil && f.Exclude[name]
+}
+
+// log returns the filter's Logger, or one that discards everything
+func (f *Filter) log() Logger {
+	if f == nil || f.Logger == nil {
+		return nopLogger{}
+	}
+	return f.Logger
+}
+
+// apiIncluded reports whether node applies to the requested API. Nodes without an api attribute apply to every
+// API; otherwise the attribute is a comma separated list of API names and must contain the filter's API exactly.
+func (f *Filter) apiIncluded(node *xmlquery.Node) bool {
+	apiAttr := node.SelectAttr("api")
+	if f == nil || f.API == "" || apiAttr == "" {
+		return true
+	}
+	for _, api := range strings.Split(apiAttr, ",") {
+		if strings.TrimSpace(api) == f.API {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSupported reports whether an <extension> node should be read for the filter's API. Extensions with
+// supported="disabled" or a supported list that does not contain the API are skipped unless they are named in Include.
+// Any other node, or a nil filter, is always supported.
+func (f *Filter) IsSupported(node *xmlquery.Node) bool {
+	if f == nil || node.Data != "extension" || f.Include[node.SelectAttr("name")] {
+		return true
+	}
+
+	supported := node.SelectAttr("supported")
+	if supported == "" {
+		return true
+	}
+	for _, api := range strings.Split(supported, ",") {
+		api = strings.TrimSpace(api)
+		if api == "disabled" {
+			return false
+		}
+		if f.API == "" || api == f.API {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package feat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestFilterIsSupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodeXML string
+		api     string
+		include string
+		want    bool
+	}{
+		{"listed api", `<extension name="VK_KHR_a" supported="vulkan,vulkansc"/>`, "vulkansc", "", true},
+		{"unlisted api", `<extension name="VK_KHR_a" supported="vulkan"/>`, "vulkansc", "", false},
+		{"disabled", `<extension name="VK_KHR_a" supported="disabled"/>`, "vulkan", "", false},
+		{"disabled but included", `<extension name="VK_KHR_a" supported="disabled"/>`, "vulkan", "VK_KHR_a", true},
+		{"unlisted api but included", `<extension name="VK_KHR_a" supported="vulkan"/>`, "vulkansc", "VK_KHR_a", true},
+		{"no supported attribute", `<extension name="VK_KHR_a"/>`, "vulkan", "", true},
+		{"no api filter", `<extension name="VK_KHR_a" supported="vulkansc"/>`, "", "", true},
+		{"feature nodes always apply", `<feature name="VK_VERSION_1_0" supported="disabled"/>`, "vulkan", "", true},
+	}
+	for _, tt := range tests {
+		doc, err := xmlquery.Parse(strings.NewReader(tt.nodeXML))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		filter := NewFilter(tt.api)
+		if tt.include != "" {
+			filter.Include[tt.include] = true
+		}
+		if got := filter.IsSupported(xmlquery.FindOne(doc, "/*")); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var nilFilter *Filter
+	if !nilFilter.IsSupported(&xmlquery.Node{Data: "extension"}) || nilFilter.IsExcluded("VK_KHR_a") {
+		t.Errorf("a nil filter must read everything")
+	}
+}
+
+const filterFixture = `<registry>
+<types>
+	<type category="struct" name="VkCore"><member><type>uint32_t</type> <name>core</name></member></type>
+	<type category="struct" name="VkEleven"><member><type>uint32_t</type> <name>eleven</name></member></type>
+</types>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require><type name="VkCore"/></require>
+</feature>
+<feature api="vulkan" name="VK_VERSION_1_1" number="1.1" depends="VK_VERSION_1_0">
+	<require><type name="VkEleven"/></require>
+</feature>
+</registry>`
+
+func TestFilterExclude(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude string
+		want    string // sorted Vk types resolved for VK_VERSION_1_1
+	}{
+		{"nothing excluded", "", "VkCore,VkEleven"},
+		{"excluded dependency", "VK_VERSION_1_0", "VkEleven"},
+	}
+	for _, tt := range tests {
+		filter := NewFilter("vulkan")
+		if tt.exclude != "" {
+			filter.Exclude[tt.exclude] = true
+		}
+		_, _, f := readResolvedFeatureFor(t, filterFixture, "VK_VERSION_1_1", filter)
+		if got := resolvedVkNames(f); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	filter := NewFilter("vulkan")
+	filter.Exclude["VK_VERSION_1_1"] = true
+	if _, _, f := readResolvedFeatureFor(t, filterFixture, "VK_VERSION_1_1", filter); f != nil {
+		t.Errorf("an excluded feature was read")
+	}
+}
@@ -0,0 +1,76 @@
+package feat
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	logrus.SetLevel(logrus.ErrorLevel)
+}
+
+// loadTestRegistry parses an inline registry document
+func loadTestRegistry(t *testing.T, registryXML string) *Registry {
+	t.Helper()
+
+	reg, err := LoadRegistry(strings.NewReader(registryXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+// readResolvedFeature reads the definitions from an inline registry document, along with the repository's
+// exceptions.json, then reads and resolves the named feature or extension for the vulkan API
+func readResolvedFeature(t *testing.T, registryXML, name string) (def.TypeRegistry, def.ValueRegistry, *Feature) {
+	t.Helper()
+	return readResolvedFeatureFor(t, registryXML, name, NewFilter("vulkan"))
+}
+
+// readResolvedFeatureFor is readResolvedFeature with the given filter. The returned feature is nil if the filter
+// excludes it.
+func readResolvedFeatureFor(t *testing.T, registryXML, name string, filter *Filter) (def.TypeRegistry, def.ValueRegistry, *Feature) {
+	t.Helper()
+
+	exceptionsBytes, err := os.ReadFile("../exceptions.json")
+	if err != nil {
+		t.Fatalf("could not read exceptions.json: %v", err)
+	}
+
+	reg := loadTestRegistry(t, registryXML)
+	reg.Filter = filter
+	reg.ReadDefinitions(gjson.ParseBytes(exceptionsBytes))
+	if filter.IsExcluded(name) {
+		if _, err := reg.ReadFeature(name); err == nil {
+			t.Errorf("reading excluded %s did not fail", name)
+		}
+		return reg.Types, reg.Values, nil
+	}
+	f, err := reg.ReadFeature(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != nil {
+		f.Resolve(reg.Types, reg.Values)
+	}
+	return reg.Types, reg.Values, f
+}
+
+// resolvedVkNames returns the sorted names of the resolved types of f that come from the fixture rather than from
+// exceptions.json
+func resolvedVkNames(f *Feature) string {
+	var names []string
+	for name := range f.ResolvedTypes {
+		if strings.HasPrefix(name, "Vk") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
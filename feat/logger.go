@@ -0,0 +1,46 @@
+package feat
+
+import "github.com/sirupsen/logrus"
+
+// Logger receives the decisions made while features are read and resolved, to explain why a type, command or value
+// was or was not generated. Set it as Filter.Logger before reading features; the features read with the filter, and
+// any feature they are merged into, report to it. If it is nil, nothing is recorded.
+type Logger interface {
+	// Required is called when name is first required, by the feature or extension origin
+	Required(name, origin string)
+	// Skipped is called when a name is left out of the output, or a feature or extension is not read
+	Skipped(name, reason string)
+	// Merged is called when the include set from resolving name is merged into a feature, with the number of types and
+	// values it resolved
+	Merged(name string, types, values int)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Required(string, string) {}
+func (nopLogger) Skipped(string, string)  {}
+func (nopLogger) Merged(string, int, int) {}
+
+// LogrusLogger is a Logger that writes each event to Entry at debug level
+type LogrusLogger struct {
+	Entry *logrus.Entry
+}
+
+func (l LogrusLogger) Required(name, origin string) {
+	l.Entry.WithField("name", name).
+		WithField("origin", origin).
+		Debug("required")
+}
+
+func (l LogrusLogger) Skipped(name, reason string) {
+	l.Entry.WithField("name", name).
+		WithField("reason", reason).
+		Debug("skipped")
+}
+
+func (l LogrusLogger) Merged(name string, types, values int) {
+	l.Entry.WithField("from", name).
+		WithField("types", types).
+		WithField("values", values).
+		Debug("merged include set")
+}
@@ -0,0 +1,98 @@
+package feat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bbredesen/vk-gen/def"
+)
+
+// ManifestEntry describes one generated Go identifier. Feature is the feature or extension that introduced the symbol,
+// see Feature.OriginOf.
+type ManifestEntry struct {
+	Name         string `json:"name"`
+	RegistryName string `json:"registryName"`
+	Category     string `json:"category"`
+	TypeName     string `json:"typeName,omitempty"`
+	Feature      string `json:"feature,omitempty"`
+}
+
+// Manifest lists the generated types and values, sorted by registry name so output from two runs can be diffed.
+type Manifest struct {
+	Types  []ManifestEntry `json:"types"`
+	Values []ManifestEntry `json:"values"`
+
+	// Files maps each generated file name to a hash of its content, not counting the header comment. A file whose
+	// hash is the same in two manifests did not change between the runs.
+	Files map[string]string `json:"files,omitempty"`
+	// SourceHashes is the same hash of each file as vk-gen wrote it, before goimports. A run with KeepUnchanged
+	// compares against these to skip formatting and rewriting files that would not change, see gen.Output.
+	SourceHashes map[string]string `json:"sourceHashes,omitempty"`
+}
+
+// Add appends the resolved types and values of f to the manifest. It should be called after Resolve and
+// RenameCollisions, so the Go names match the generated code.
+func (m *Manifest) Add(f *Feature) {
+	for _, td := range f.SortedTypes() {
+		if td.Category() == def.CatInclude {
+			continue
+		}
+		m.Types = append(m.Types, ManifestEntry{
+			Name:         td.PublicName(),
+			RegistryName: td.RegistryName(),
+			Category:     categoryName(td.Category()),
+			Feature:      f.OriginOf(td.RegistryName()),
+		})
+	}
+
+	for typeName, vals := range f.ResolvedValues {
+		for _, vd := range vals {
+			cat := def.CatExten
+			if vd.ResolvedType() != nil {
+				cat = vd.ResolvedType().Category()
+			}
+			m.Values = append(m.Values, ManifestEntry{
+				Name:         vd.PublicName(),
+				RegistryName: vd.RegistryName(),
+				Category:     categoryName(cat),
+				TypeName:     typeName,
+				Feature:      f.OriginOf(vd.RegistryName()),
+			})
+		}
+	}
+
+	m.sort()
+}
+
+func (m *Manifest) sort() {
+	for _, entries := range [][]ManifestEntry{m.Types, m.Values} {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].RegistryName < entries[j].RegistryName })
+	}
+}
+
+func categoryName(tc def.TypeCategory) string { return strings.TrimPrefix(tc.String(), "Cat") }
+
+// ReadManifestFile reads a manifest written by WriteFile
+func ReadManifestFile(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("could not parse manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// WriteFile writes the manifest to path as indented JSON
+func (m *Manifest) WriteFile(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
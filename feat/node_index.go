@@ -0,0 +1,36 @@
+package feat
+
+import (
+	"github.com/antchfx/xmlquery"
+)
+
+// nodeIndex maps the names of <feature> and <extension> nodes to the nodes themselves, so dependencies can be
+// looked up without querying the whole document each time
+type nodeIndex map[string]*xmlquery.Node
+
+// newNodeIndex walks the document once and indexes its features and extensions. The index is not cached; Registry
+// keeps the one for its document, and ReadFeaturesInRange builds one per call.
+func newNodeIndex(root *xmlquery.Node) nodeIndex {
+	idx := make(nodeIndex)
+	for _, n := range xmlquery.Find(root, "//extensions/extension") {
+		idx[n.SelectAttr("name")] = n
+	}
+	// Features take precedence if a name were ever used for both
+	for _, n := range xmlquery.Find(root, "//feature") {
+		idx[n.SelectAttr("name")] = n
+	}
+	return idx
+}
+
+// FindNode returns the <feature> or <extension> node with the given name, or nil if there is no such node. Lookups
+// use the same index as dependency resolution instead of an XPath query per name.
+func (reg *Registry) FindNode(name string) *xmlquery.Node {
+	return reg.index[name]
+}
+
+func documentRoot(n *xmlquery.Node) *xmlquery.Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
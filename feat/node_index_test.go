@@ -0,0 +1,47 @@
+package feat
+
+import "testing"
+
+func TestFindNode(t *testing.T) {
+	reg := loadTestRegistry(t, `<registry>
+	<feature api="vulkan" name="VK_VERSION_1_0" number="1.0"/>
+	<extensions>
+		<extension name="VK_KHR_surface" number="1" supported="vulkan"/>
+	</extensions>
+</registry>`)
+	vendor := loadTestRegistry(t, `<registry>
+	<extensions>
+		<extension name="VK_VENDOR_example" number="9000" supported="vulkan"/>
+	</extensions>
+</registry>`)
+
+	tests := []struct {
+		reg      *Registry
+		name     string
+		wantNode string // "" for no node
+	}{
+		{reg, "VK_VERSION_1_0", "feature"},
+		{reg, "VK_KHR_surface", "extension"},
+		{reg, "VK_VENDOR_example", ""},
+		{reg, "VK_KHR_missing", ""},
+		// Each registry has its own index
+		{vendor, "VK_VENDOR_example", "extension"},
+		{vendor, "VK_VERSION_1_0", ""},
+	}
+	for _, tt := range tests {
+		n := tt.reg.FindNode(tt.name)
+		switch {
+		case n == nil && tt.wantNode != "":
+			t.Errorf("%s: not found", tt.name)
+		case n != nil && n.Data != tt.wantNode:
+			t.Errorf("%s: got <%s>, want %q", tt.name, n.Data, tt.wantNode)
+		}
+	}
+
+	if _, err := reg.Merge(vendor, "vendor.xml"); err != nil {
+		t.Fatal(err)
+	}
+	if n := reg.FindNode("VK_VENDOR_example"); n == nil || n.SelectAttr("number") != "9000" {
+		t.Errorf("merged extension is not indexed")
+	}
+}
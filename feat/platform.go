@@ -14,11 +14,33 @@ type Platform struct {
 
 	GoBuildTag string
 	GoImports  []string
+	// goFileSuffix replaces the platform name in the names of the platform's files, see FileSuffix
+	goFileSuffix string
 
 	platformExtensionNames map[string]bool
 	extensions             map[string]*Extension
 }
 
+// ReadPlatforms builds the platform registry from the <platforms> of a registry document and the platform section
+// of exceptions.json. The general platform, for extensions that are not tied to a platform, is registered as "".
+func ReadPlatforms(root *xmlquery.Node, exceptions gjson.Result) PlatformRegistry {
+	platforms := make(PlatformRegistry)
+	platforms[""] = NewGeneralPlatform()
+	for _, n := range xmlquery.Find(root, "//platforms/platform") {
+		plat := NewPlatformFromXML(n)
+		platforms[plat.Name()] = plat
+	}
+	exceptions.Get("platform").ForEach(func(key, value gjson.Result) bool {
+		if key.String() == "!comment" {
+			return true
+		}
+		r := NewOrUpdatePlatformFromJSON(key.String(), value, platforms[key.String()])
+		platforms[r.Name()] = r
+		return true
+	})
+	return platforms
+}
+
 func NewGeneralPlatform() *Platform {
 	rval := Platform{
 		platformName:           "",
@@ -53,6 +75,7 @@ func NewOrUpdatePlatformFromJSON(key string, exception gjson.Result, existing *P
 
 	// static mapping vk platform to go build tags
 	updatedEntry.GoBuildTag = exception.Get("go:build").String()
+	updatedEntry.goFileSuffix = exception.Get("go:fileSuffix").String()
 	exception.Get("go:imports").ForEach(func(_, val gjson.Result) bool {
 		updatedEntry.GoImports = append(updatedEntry.GoImports, val.String())
 		return true
@@ -63,6 +86,15 @@ func NewOrUpdatePlatformFromJSON(key string, exception gjson.Result, existing *P
 
 func (p *Platform) Name() string { return p.platformName }
 
+// FileSuffix is appended to the category name of each file generated for the platform, e.g. struct_win32.go. It is the
+// platform name unless exceptions.json sets go:fileSuffix, as for the provisional platform's _beta files.
+func (p *Platform) FileSuffix() string {
+	if p.goFileSuffix != "" {
+		return p.goFileSuffix
+	}
+	return p.platformName
+}
+
 func (p *Platform) IncludeExtension(e *Extension) {
 	p.extensions[e.Name()] = e
 }
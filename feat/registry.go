@@ -0,0 +1,282 @@
+package feat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/tidwall/gjson"
+)
+
+// Registry is a parsed registry document (vk.xml) along with the type and value registries read from it. Features
+// and extensions are looked up through the same node index used for dependency resolution, so the document is only
+// parsed and walked once however many features are read.
+type Registry struct {
+	Root   *xmlquery.Node
+	Types  def.TypeRegistry
+	Values def.ValueRegistry
+
+	// Filter selects the API and the extensions to read. It is set to NewFilter("vulkan") by LoadRegistry and may be
+	// replaced before ReadDefinitions is called.
+	Filter *Filter
+
+	index           nodeIndex
+	definitionsRead bool
+}
+
+// LoadRegistry parses a registry document from r. The type and value registries are empty until ReadDefinitions is
+// called, or until the first call to ReadFeature.
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	root, err := xmlquery.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse registry XML: %w", err)
+	}
+
+	return &Registry{
+		Root:   root,
+		Types:  make(def.TypeRegistry),
+		Values: make(def.ValueRegistry),
+		Filter: NewFilter("vulkan"),
+		index:  newNodeIndex(root),
+	}, nil
+}
+
+// LoadRegistryFile opens and parses the registry document at path, see LoadRegistry
+func LoadRegistryFile(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadRegistry(f)
+}
+
+// LoadRegistryFiles loads the registry document at the first path and merges each of the others into it, in order,
+// see Merge. Collisions are returned rather than treated as errors.
+func LoadRegistryFiles(paths ...string) (*Registry, []MergeCollision, error) {
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no registry files to load")
+	}
+	reg, err := LoadRegistryFile(paths[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var collisions []MergeCollision
+	for _, path := range paths[1:] {
+		other, err := LoadRegistryFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		c, err := reg.Merge(other, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		collisions = append(collisions, c...)
+	}
+	return reg, collisions, nil
+}
+
+// MergeCollision is a name defined by more than one merged registry document. The definition already in the registry
+// is kept, and the one from Source is dropped.
+type MergeCollision struct {
+	Kind   string // "type", "command", "value", "feature", "extension", "platform" or "tag"
+	Name   string
+	Source string
+}
+
+// Merge moves the definitions of other, e.g. a vendor extension registry, into reg, so that the features and
+// extensions of either can require the types and values of both. The node index is rebuilt afterwards. Definitions
+// whose name is already in reg are dropped and returned as collisions; values in an <enums> block that reg already
+// has, like "API Constants", are added to reg's block. Merge must be called before ReadDefinitions, and other should
+// not be used afterwards. source names other in the collisions.
+func (reg *Registry) Merge(other *Registry, source string) ([]MergeCollision, error) {
+	if reg.definitionsRead {
+		return nil, fmt.Errorf("cannot merge %s after the registry's definitions have been read", source)
+	}
+	dst, src := xmlquery.FindOne(reg.Root, "/registry"), xmlquery.FindOne(other.Root, "/registry")
+	if dst == nil || src == nil {
+		return nil, fmt.Errorf("cannot merge %s: both documents need a <registry> element", source)
+	}
+
+	defined := make(map[definitionKey]*xmlquery.Node)
+	for _, section := range elementChildren(dst) {
+		if key, ok := definitionKeyOf(section); ok {
+			defined[key] = section
+		}
+		for _, n := range elementChildren(section) {
+			if key, ok := definitionKeyOf(n); ok {
+				defined[key] = n
+			}
+		}
+	}
+
+	var collisions []MergeCollision
+	collide := func(key definitionKey) {
+		collisions = append(collisions, MergeCollision{Kind: key.kind, Name: key.name, Source: source})
+	}
+
+	for _, section := range elementChildren(src) {
+		sectionKey, named := definitionKeyOf(section)
+		existing := defined[sectionKey]
+		if named && existing != nil && sectionKey.kind != "enums" {
+			collide(sectionKey)
+			continue
+		}
+
+		for _, n := range elementChildren(section) {
+			key, ok := definitionKeyOf(n)
+			if !ok {
+				continue
+			}
+			if defined[key] != nil {
+				collide(key)
+				xmlquery.RemoveFromTree(n)
+				continue
+			}
+			defined[key] = n
+			if existing != nil {
+				xmlquery.RemoveFromTree(n)
+				xmlquery.AddChild(existing, n)
+			}
+		}
+
+		if existing == nil {
+			xmlquery.RemoveFromTree(section)
+			xmlquery.AddChild(dst, section)
+			if named {
+				defined[sectionKey] = section
+			}
+		}
+	}
+
+	reg.index = newNodeIndex(reg.Root)
+
+	return collisions, nil
+}
+
+type definitionKey struct{ kind, name string }
+
+// definitionKeyOf returns the kind and name of a definition in a registry document, either a top level element
+// (<feature>, <enums>) or a child of one (<type>, <command>, <enum>, <extension>, <platform>, <tag>)
+func definitionKeyOf(n *xmlquery.Node) (definitionKey, bool) {
+	name := n.SelectAttr("name")
+	kind := n.Data
+
+	switch n.Data {
+	case "type":
+		if name == "" {
+			if nameNode := xmlquery.FindOne(n, "name"); nameNode != nil {
+				name = nameNode.InnerText()
+			}
+		}
+	case "command":
+		if name == "" {
+			if nameNode := xmlquery.FindOne(n, "proto/name"); nameNode != nil {
+				name = nameNode.InnerText()
+			}
+		}
+	case "enum":
+		kind = "value"
+	case "enums", "feature", "extension", "platform", "tag":
+	default:
+		return definitionKey{}, false
+	}
+
+	if name == "" {
+		return definitionKey{}, false
+	}
+	return definitionKey{kind, name}, true
+}
+
+func elementChildren(n *xmlquery.Node) []*xmlquery.Node {
+	var children []*xmlquery.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == xmlquery.ElementNode {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// ReadDefinitions reads every type category from the document, applying the matching sections of exceptions
+// (exceptions.json), and then registers the values defined by all features and extensions, see
+// CollectExtendedValues. Only definitions for the filter's API are read. It does nothing if the definitions have
+// already been read.
+func (reg *Registry) ReadDefinitions(exceptions gjson.Result) {
+	if reg.definitionsRead {
+		return
+	}
+	reg.definitionsRead = true
+
+	for tc := def.CatNone; tc < def.CatMaximum; tc++ {
+		xml, json := tc.ReadFns()
+		if xml != nil {
+			xml(reg.Root, reg.Types, reg.Values, reg.Filter.API)
+		}
+		if json != nil {
+			json(exceptions, reg.Types, reg.Values)
+		}
+	}
+
+	CollectExtendedValues(reg.Root, reg.Types, reg.Values, reg.Filter)
+}
+
+// ReadFeature reads the named <feature> or <extension>. Features are read together with the features they depend on;
+// an extension is read on its own, as main does for each extension. The definitions are read without exceptions if
+// ReadDefinitions has not been called yet. The returned feature is not resolved.
+func (reg *Registry) ReadFeature(name string) (*Feature, error) {
+	reg.ReadDefinitions(gjson.Result{})
+
+	node := reg.index[name]
+	if node == nil {
+		return nil, fmt.Errorf("no feature or extension named %s in the registry", name)
+	}
+	if reg.Filter.IsExcluded(name) {
+		return nil, fmt.Errorf("%s is excluded by the filter", name)
+	}
+
+	if node.Data == "extension" {
+		if !reg.Filter.IsSupported(node) {
+			return nil, fmt.Errorf("extension %s is not supported for API %s", name, reg.Filter.API)
+		}
+		return ReadExtensionFromXML(node, reg.Types, reg.Values, reg.Filter).Feature, nil
+	}
+	return readFeatureFromXMLWithDeps(node, reg.index, reg.Types, reg.Values, reg.Filter, make(map[string]bool)), nil
+}
+
+// Revision describes the version of the registry document, for the header of generated files. It is built from the
+// value of VK_HEADER_VERSION, e.g. "VK_HEADER_VERSION 296", followed by any line of the registry's top level <comment>
+// that mentions a revision or commit. It is empty if neither is found.
+func (reg *Registry) Revision() string {
+	var parts []string
+
+	for _, n := range xmlquery.Find(reg.Root, "//types/type[@category='define']/name") {
+		if n.InnerText() != "VK_HEADER_VERSION" {
+			continue
+		}
+		if m := rxHeaderVersion.FindStringSubmatch(n.Parent.InnerText()); m != nil {
+			parts = append(parts, "VK_HEADER_VERSION "+m[1])
+		}
+		break
+	}
+
+	if c := xmlquery.FindOne(reg.Root, "/registry/comment"); c != nil {
+		for _, line := range strings.Split(c.InnerText(), "\n") {
+			lower := strings.ToLower(line)
+			if strings.Contains(lower, "revision") || strings.Contains(lower, "commit") {
+				parts = append(parts, strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+var rxHeaderVersion = regexp.MustCompile(`VK_HEADER_VERSION\s+(\d+)`)
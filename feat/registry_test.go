@@ -0,0 +1,168 @@
+package feat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/tidwall/gjson"
+)
+
+const mergeBaseFixture = `<registry>
+<tags>
+	<tag name="KHR" author="Khronos" contact="none"/>
+</tags>
+<types>
+	<type requires="vk_platform" name="uint32_t"/>
+	<type category="struct" name="VkBase"><member><type>uint32_t</type> <name>base</name></member></type>
+</types>
+<enums name="API Constants">
+	<enum type="uint32_t" value="16" name="VK_MAX_A"/>
+</enums>
+<commands>
+	<command><proto><type>void</type> <name>vkBaseCmd</name></proto></command>
+</commands>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require><type name="VkBase"/></require>
+</feature>
+<extensions>
+	<extension name="VK_KHR_base" number="1" supported="vulkan"/>
+</extensions>
+</registry>`
+
+const mergeVideoFixture = `<registry>
+<tags>
+	<tag name="KHR" author="Khronos" contact="none"/>
+</tags>
+<types>
+	<type category="struct" name="StdVideoH264"><member><type>uint32_t</type> <name>profile</name></member></type>
+	<type category="struct" name="VkBase"><member><type>uint64_t</type> <name>other</name></member></type>
+</types>
+<enums name="API Constants">
+	<enum type="uint32_t" value="32" name="VK_MAX_A"/>
+	<enum type="uint32_t" value="8" name="VK_MAX_B"/>
+</enums>
+<commands>
+	<command><proto><type>void</type> <name>vkBaseCmd</name></proto></command>
+</commands>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0"/>
+<extensions>
+	<extension name="VK_KHR_base" number="1" supported="vulkan"/>
+	<extension name="VK_KHR_video" number="2" supported="vulkan">
+		<require>
+			<type name="StdVideoH264"/>
+			<type name="VkBase"/>
+			<enum name="VK_MAX_A"/>
+			<enum name="VK_MAX_B"/>
+		</require>
+	</extension>
+</extensions>
+</registry>`
+
+// publicDeclaration returns the public declaration of td, without its doc comment
+func publicDeclaration(td def.TypeDefiner) string {
+	sb := &strings.Builder{}
+	td.PrintPublicDeclaration(sb)
+	_, decl, _ := strings.Cut(sb.String(), "\n")
+	return strings.TrimSpace(decl)
+}
+
+func TestMerge(t *testing.T) {
+	reg := loadTestRegistry(t, mergeBaseFixture)
+	collisions, err := reg.Merge(loadTestRegistry(t, mergeVideoFixture), "video.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCollisions := []MergeCollision{
+		{"tag", "KHR", "video.xml"},
+		{"type", "VkBase", "video.xml"},
+		{"value", "VK_MAX_A", "video.xml"},
+		{"command", "vkBaseCmd", "video.xml"},
+		{"feature", "VK_VERSION_1_0", "video.xml"},
+		{"extension", "VK_KHR_base", "video.xml"},
+	}
+	if !reflect.DeepEqual(collisions, wantCollisions) {
+		t.Errorf("collisions: got %v, want %v", collisions, wantCollisions)
+	}
+
+	exceptionsBytes, err := os.ReadFile("../exceptions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.ReadDefinitions(gjson.ParseBytes(exceptionsBytes))
+
+	// The merged extension is found through the rebuilt index, and requires names from both documents
+	f, err := reg.ReadFeature("VK_KHR_video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Resolve(reg.Types, reg.Values)
+	if names := f.UnresolvedNames(); len(names) != 0 {
+		t.Errorf("unresolved names %v", names)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		// Colliding definitions keep the one already in the registry
+		{"VkBase", publicDeclaration(reg.Types["VkBase"]), "type Base struct {\nBase uint32\n}"},
+		{"VK_MAX_A", reg.Values["VK_MAX_A"].ValueString(), "16"},
+		// Values of a shared <enums> block are added to the existing block
+		{"VK_MAX_B", reg.Values["VK_MAX_B"].ValueString(), "8"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+	if f.ResolvedTypes["StdVideoH264"] == nil {
+		t.Errorf("StdVideoH264 is not resolved")
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	reg := loadTestRegistry(t, mergeBaseFixture)
+	if _, err := reg.Merge(loadTestRegistry(t, `<notaregistry/>`), "bad.xml"); err == nil {
+		t.Errorf("merging a document without a <registry> element did not fail")
+	}
+
+	reg.ReadDefinitions(gjson.Result{})
+	if _, err := reg.Merge(loadTestRegistry(t, mergeVideoFixture), "video.xml"); err == nil {
+		t.Errorf("merging after ReadDefinitions did not fail")
+	}
+}
+
+func TestLoadRegistryFiles(t *testing.T) {
+	if _, _, err := LoadRegistryFiles(); err == nil {
+		t.Errorf("loading no files did not fail")
+	}
+
+	dir := t.TempDir()
+	basePath, videoPath := filepath.Join(dir, "vk.xml"), filepath.Join(dir, "video.xml")
+	for path, content := range map[string]string{basePath: mergeBaseFixture, videoPath: mergeVideoFixture} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := LoadRegistryFiles(basePath, filepath.Join(dir, "missing.xml")); err == nil {
+		t.Errorf("loading a missing file did not fail")
+	}
+
+	reg, collisions, err := LoadRegistryFiles(basePath, videoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collisions) != 6 || collisions[0].Source != videoPath {
+		t.Errorf("collisions: got %v, want 6 from %s", collisions, videoPath)
+	}
+	if _, err := reg.ReadFeature("VK_KHR_video"); err != nil {
+		t.Errorf("merged extension: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package feat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bbredesen/vk-gen/def"
+)
+
+// ValidationKind is the kind of problem found by Validate
+type ValidationKind string
+
+const (
+	ValidationUnresolved    ValidationKind = "required but not defined in the registry"
+	ValidationUntypedValue  ValidationKind = "value's type is not generated"
+	ValidationValueConflict ValidationKind = "value has conflicting definitions"
+	ValidationDanglingAlias ValidationKind = "alias target not found in the registry"
+)
+
+// ValidationError is one problem found by Validate. Detail is extra context for the problem, like the missing type
+// name or the conflicting values, and may be empty.
+type ValidationError struct {
+	Kind         ValidationKind
+	RegistryName string
+	Detail       string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s: %s", e.RegistryName, e.Kind)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.RegistryName, e.Kind, e.Detail)
+}
+
+// Validate runs each of the consistency checks on a resolved feature and returns every problem found as a
+// *ValidationError, sorted by kind and then by registry name. It returns nil if the feature is ready to be printed.
+// base is passed to CheckValueTypes. Validate only reports; name collisions are fixed by RenameCollisions instead.
+func (f *Feature) Validate(base ...*Feature) []error {
+	var errs []*ValidationError
+
+	for _, n := range f.UnresolvedNames() {
+		errs = append(errs, &ValidationError{Kind: ValidationUnresolved, RegistryName: n})
+	}
+
+	for _, u := range f.CheckValueTypes(base...) {
+		errs = append(errs, &ValidationError{Kind: ValidationUntypedValue, RegistryName: u.RegistryName, Detail: "type " + u.TypeName})
+	}
+
+	if err := f.CheckValueConflicts(); err != nil {
+		for _, c := range err.(*ValueConflictError).Conflicts {
+			errs = append(errs, &ValidationError{
+				Kind:         ValidationValueConflict,
+				RegistryName: c.RegistryName,
+				Detail:       fmt.Sprintf("types: %v; values: %v", c.TypeNames, c.Values),
+			})
+		}
+	}
+
+	for _, td := range f.ResolvedTypes {
+		if d, ok := td.(def.DanglingAliaser); ok && d.DanglingAlias() != "" {
+			errs = append(errs, &ValidationError{Kind: ValidationDanglingAlias, RegistryName: td.RegistryName(), Detail: "alias of " + d.DanglingAlias()})
+		}
+	}
+	for _, vals := range f.ResolvedValues {
+		for _, v := range vals {
+			if d, ok := v.(def.DanglingAliaser); ok && d.DanglingAlias() != "" {
+				errs = append(errs, &ValidationError{Kind: ValidationDanglingAlias, RegistryName: v.RegistryName(), Detail: "alias of " + d.DanglingAlias()})
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Kind != errs[j].Kind {
+			return errs[i].Kind < errs[j].Kind
+		}
+		return errs[i].RegistryName < errs[j].RegistryName
+	})
+	rval := make([]error, len(errs))
+	for i, e := range errs {
+		rval[i] = e
+	}
+	return rval
+}
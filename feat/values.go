@@ -0,0 +1,57 @@
+package feat
+
+import (
+	"strconv"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/bbredesen/vk-gen/def"
+)
+
+// CollectExtendedValues registers every value defined in a <require> block of any feature or extension in the
+// document, without requiring any of them. Aliases are only resolved after all features are read, but the value they
+// point at may be defined by an extension that is never read, for example one that is excluded or belongs to a platform
+// that is not generated. Collecting the values first means each alias can always find its target, whatever order
+// features and extensions are read in. Nodes are selected by the filter's API only.
+func CollectExtendedValues(root *xmlquery.Node, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter) {
+	for _, fn := range xmlquery.Find(root, "//feature|//extensions/extension") {
+		if !filter.apiIncluded(fn) {
+			continue
+		}
+
+		// Features have a version in their number attribute, and offsets only apply to extension numbers
+		extNum := 0
+		if fn.Data == "extension" {
+			extNum, _ = strconv.Atoi(fn.SelectAttr("number"))
+		}
+		sourceName := fn.SelectAttr("name")
+
+		for _, enumNode := range xmlquery.Find(fn, "/require/enum") {
+			if !filter.apiIncluded(enumNode) || !filter.apiIncluded(enumNode.Parent) {
+				continue
+			}
+			if vd := newRequiredValueFromXML(enumNode, tr, extNum); vd != nil {
+				registerExtendedValue(vr, vd, sourceName)
+			}
+		}
+	}
+}
+
+// newRequiredValueFromXML creates the value defined by an <enum> node in a <require> block. It returns nil if the node
+// only refers to a value defined elsewhere, like VK_SHADER_UNUSED_KHR.
+func newRequiredValueFromXML(enumNode *xmlquery.Node, tr def.TypeRegistry, extNum int) def.ValueDefiner {
+	extendsTypeName := enumNode.SelectAttr("extends")
+	if extendsTypeName == "" && enumNode.SelectAttr("value") == "" && enumNode.SelectAttr("alias") == "" {
+		return nil
+	}
+
+	var vd def.ValueDefiner
+	if td, found := tr[extendsTypeName]; found && enumNode.SelectAttr("bitpos") != "" {
+		vd = def.NewBitmaskValueFromXML(td, enumNode)
+	} else if found {
+		vd = def.NewEnumValueFromXML(td, enumNode)
+	} else {
+		vd = def.NewUntypedEnumValueFromXML(enumNode)
+	}
+	vd.SetExtensionNumber(extNum)
+	return vd
+}
@@ -0,0 +1,66 @@
+package feat
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/sirupsen/logrus"
+)
+
+// ReadFeaturesInRange reads every <feature> whose number is between minVersion and maxVersion inclusive, along with
+// their dependencies, and merges them into one Feature. Either bound may be empty to leave that end open, so
+// ReadFeaturesInRange(root, "", "1.2", ...) reads core Vulkan up to 1.2. Features excluded by the filter's API are
+// skipped as usual.
+func ReadFeaturesInRange(root *xmlquery.Node, minVersion, maxVersion string, tr def.TypeRegistry, vr def.ValueRegistry, filter *Filter) *Feature {
+	rval := NewFeature()
+	index := newNodeIndex(root)
+
+	for _, n := range xmlquery.Find(root, "//feature") {
+		number := n.SelectAttr("number")
+		if minVersion != "" && CompareVersions(number, minVersion) < 0 ||
+			maxVersion != "" && CompareVersions(number, maxVersion) > 0 {
+			continue
+		}
+
+		f := readFeatureFromXMLWithDeps(n, index, tr, vr, filter, make(map[string]bool))
+		if f == nil {
+			continue
+		}
+		if rval.featureName == "" || CompareVersions(f.version, rval.version) > 0 {
+			rval.featureName, rval.version = f.featureName, f.version
+		}
+		rval.MergeWith(f)
+	}
+
+	return rval
+}
+
+// CompareVersions compares two dotted version numbers like "1.3" component by component, returning -1, 0 or 1. A
+// missing component counts as zero, so "1" and "1.0" are equal.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		av, bv := versionComponent(as, i), versionComponent(bs, i)
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponent(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+	if err != nil {
+		logrus.WithField("version", strings.Join(parts, ".")).
+			WithError(err).
+			Warn("could not parse version number component, treating it as zero")
+	}
+	return v
+}
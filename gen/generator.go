@@ -0,0 +1,425 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/bbredesen/vk-gen/feat"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// Options configures a Generator. The zero value generates package vk for the vulkan API, without exceptions, static
+// files or platforms.
+type Options struct {
+	// API is the API to generate against, "vulkan" if empty
+	API string
+	// Platforms lists the Vulkan platform names, e.g. "win32", to generate platform files for
+	Platforms []string
+
+	PackageName, ImportPath string
+	// Source names the registry in the header of each generated file, "vk.xml" if empty
+	Source string
+
+	// Exceptions is the parsed exceptions.json
+	Exceptions gjson.Result
+	// StaticDir is the directory of static files (static_include) to copy into the output; nothing is copied if empty
+	StaticDir string
+	// GoimportsPath is the goimports binary to format files with. If empty, it is looked up with FindGoimports.
+	GoimportsPath string
+	// VideoFile is the Vulkan Video registry (video.xml) to read the StdVideo types from. If empty, those types are
+	// mapped as exceptions.json says.
+	VideoFile string
+
+	// NameTransform, if set, replaces the Go identifiers derived from registry names, see def.NameTransform. The
+	// static files refer to types and values like Result and SUCCESS by their default names and declare others, like
+	// ChainNode, so new names that would break them are not applied, see feat.RejectedName.
+	NameTransform def.NameTransform
+
+	// These select the optional parts of the generated code, see the def.PrintOptions fields of the same purpose
+	MustWrappers, HandleMethods  bool // def.PrintOptions.MustWrappers, HandleMethods
+	UntypedEnums, TypedConstants bool // def.PrintOptions.UntypedEnumValues, TypedAPIConstants
+	// ShortEnumNames strips the prefix shared by the values of each enum, see def.AssignShortValueNames
+	ShortEnumNames bool
+
+	// CommandTable, SymbolTable and StructSizeTest also write command_table.go, symbol_table.go and
+	// struct_size_test.go, see the Output methods of the same names
+	CommandTable, SymbolTable, StructSizeTest bool
+	// KeepUnchanged leaves files whose content would not change untouched, see Output.KeepUnchanged
+	KeepUnchanged bool
+
+	// Logger, if set, records why each feature, extension and name is read or skipped, see feat.Logger
+	Logger feat.Logger
+
+	// Strict makes Generate return the validation errors and name collisions of the selected features instead of
+	// logging them
+	Strict bool
+}
+
+// Generator generates a package from a registry, as the command line tool does, for use from other programs:
+//
+//	g := gen.NewGenerator(registry, gen.Options{Exceptions: exceptions, StaticDir: "static_include"})
+//	g.SelectVersionRange("", "1.2")
+//	g.SelectFeature("VK_KHR_swapchain")
+//	err := g.Generate("vk")
+//
+// If nothing is selected, every core version and every extension supported for the API is generated. A Generator
+// reads the definitions of its registry and resolves the selected features on the first call to Generate or DryRun.
+// Later calls reuse the result, so features selected after that are not generated.
+type Generator struct {
+	Options
+
+	registry *feat.Registry
+
+	featureNames           []string
+	minVersion, maxVersion string
+	versionRangeSelected   bool
+	allExtensions          bool
+	onlyNames              []string
+	excludeNames           []string
+	includeNames           []string
+
+	// resolved and resolveErr hold the result of the first call to resolve
+	resolved   *resolved
+	resolveErr error
+}
+
+// NewGenerator returns a Generator for the registry, which must not have read its definitions yet
+func NewGenerator(registry *feat.Registry, opts Options) *Generator {
+	if opts.API == "" {
+		opts.API = "vulkan"
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = "vk"
+	}
+	if opts.Source == "" {
+		opts.Source = "vk.xml"
+	}
+	return &Generator{Options: opts, registry: registry}
+}
+
+// SelectFeature adds a feature, e.g. VK_VERSION_1_1, or an extension to the output. Features are generated with the
+// features they depend on. Extensions for a platform are only generated if the platform is listed in Options.
+func (g *Generator) SelectFeature(name string) {
+	g.featureNames = append(g.featureNames, name)
+}
+
+// SelectVersionRange adds the core versions from min to max to the output. Either may be empty to leave that end
+// open, see feat.ReadFeaturesInRange.
+func (g *Generator) SelectVersionRange(min, max string) {
+	g.minVersion, g.maxVersion = min, max
+	g.versionRangeSelected = true
+}
+
+// SelectAllExtensions adds every extension supported for the API to the output, as when nothing is selected. Use it
+// with SelectVersionRange to limit the core versions but not the extensions.
+func (g *Generator) SelectAllExtensions() {
+	g.allExtensions = true
+}
+
+// SelectNames generates just the named types, commands and values and their dependencies, instead of the selected
+// features. Every feature and extension is still read, so that the values they add can be named, but no platform
+// files are generated.
+func (g *Generator) SelectNames(names ...string) {
+	g.onlyNames = append(g.onlyNames, names...)
+}
+
+// Exclude leaves the named features and extensions out of the output, even if they are selected
+func (g *Generator) Exclude(names ...string) {
+	g.excludeNames = append(g.excludeNames, names...)
+}
+
+// Include reads the named extensions even if they are disabled or not supported for the API, see feat.Filter
+func (g *Generator) Include(names ...string) {
+	g.includeNames = append(g.includeNames, names...)
+}
+
+// resolved holds the resolved features of a Generator, ready to be written or reported
+type resolved struct {
+	tr        def.TypeRegistry
+	vr        def.ValueRegistry
+	core      *feat.Feature
+	platforms map[string]*feat.Platform
+	// platformNames lists the generated platforms in the order of Options.Platforms, with their features
+	platformNames    []string
+	platformFeatures map[string]*feat.Feature
+}
+
+// resolve reads and resolves the selected features on the first call, and returns the same result on every call after
+// that. Validation problems are logged, or returned if Strict is set.
+func (g *Generator) resolve() (*resolved, error) {
+	if g.resolved == nil && g.resolveErr == nil {
+		g.resolved, g.resolveErr = g.resolveFeatures()
+	}
+	return g.resolved, g.resolveErr
+}
+
+func (g *Generator) resolveFeatures() (*resolved, error) {
+	filter := feat.NewFilter(g.API)
+	filter.Logger = g.Logger
+	for _, name := range g.excludeNames {
+		filter.Exclude[name] = true
+	}
+	for _, name := range g.includeNames {
+		filter.Include[name] = true
+	}
+	reg := g.registry
+	reg.Filter = filter
+	// Aliases may point at values from extensions that are never read, so every value is registered up front, along
+	// with the types
+	reg.ReadDefinitions(g.Exceptions)
+	tr, vr := reg.Types, reg.Values
+
+	videoValues := def.NewIncludeSet()
+	if g.VideoFile != "" {
+		var err error
+		if videoValues, err = g.readVideoRegistry(tr, vr); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &resolved{tr: tr, vr: vr, platforms: feat.ReadPlatforms(reg.Root, g.Exceptions), platformFeatures: make(map[string]*feat.Feature)}
+	generatePlatform := map[string]bool{"": true}
+	if len(g.onlyNames) == 0 {
+		for _, name := range g.Platforms {
+			if p := r.platforms[name]; p == nil {
+				return nil, fmt.Errorf("platform %s is not defined in the registry or exceptions", name)
+			} else if p.GoBuildTag == "!ignore" {
+				return nil, fmt.Errorf("platform %s has no Go build target", name)
+			}
+			generatePlatform[name] = true
+			r.platformNames = append(r.platformNames, name)
+		}
+	}
+
+	includeExtension := func(extNode *xmlquery.Node) {
+		ext := feat.ReadExtensionFromXML(extNode, tr, vr, filter)
+		if generatePlatform[ext.PlatformName()] {
+			r.platforms[ext.PlatformName()].IncludeExtension(ext)
+		}
+	}
+
+	r.core = feat.NewFeature()
+	selectAll := len(g.featureNames) == 0 && !g.versionRangeSelected && !g.allExtensions
+	if selectAll || g.versionRangeSelected || len(g.onlyNames) > 0 {
+		r.core.MergeWith(feat.ReadFeaturesInRange(reg.Root, g.minVersion, g.maxVersion, tr, vr, filter))
+	}
+	if selectAll || g.allExtensions || len(g.onlyNames) > 0 {
+		for _, extNode := range xmlquery.Find(reg.Root, "//extensions/extension") {
+			if filter.IsExcluded(extNode.SelectAttr("name")) || !filter.IsSupported(extNode) {
+				continue
+			}
+			includeExtension(extNode)
+		}
+	}
+	for _, name := range g.featureNames {
+		if filter.IsExcluded(name) {
+			continue
+		}
+		if extNode := reg.FindNode(name); extNode != nil && extNode.Data == "extension" {
+			if !filter.IsSupported(extNode) {
+				return nil, fmt.Errorf("extension %s is not supported for API %s", name, g.API)
+			}
+			includeExtension(extNode)
+			continue
+		}
+		f, err := reg.ReadFeature(name)
+		if err != nil {
+			return nil, err
+		}
+		r.core.MergeWith(f)
+	}
+
+	if len(g.onlyNames) > 0 {
+		r.core = feat.NewFeatureFromNames(g.onlyNames, tr, vr)
+	} else {
+		r.core.MergeWith(r.platforms[""].GeneratePlatformFeatures())
+	}
+	r.core.MergeIncludeSet(tr.SelectCategory(def.CatExternal))
+	r.core.MergeIncludeSet(videoValues)
+
+	// All extension values are registered by now, and nothing has been resolved yet
+	def.SetPrintOptions(tr, vr, def.PrintOptions{
+		MustWrappers:      g.MustWrappers,
+		HandleMethods:     g.HandleMethods,
+		UntypedEnumValues: g.UntypedEnums,
+		TypedAPIConstants: g.TypedConstants,
+	})
+	if g.ShortEnumNames {
+		def.AssignShortValueNames(tr, vr)
+	}
+
+	r.core.Resolve(tr, vr)
+	problems := g.checkFeature(r.core, "core")
+
+	for _, name := range r.platformNames {
+		pf := r.platforms[name].GeneratePlatformFeatures()
+		pf.IncludeFeaturesFrom(r.core)
+		pf.Resolve(tr, vr)
+		problems = append(problems, g.checkFeature(pf, name, r.core)...)
+		r.platformFeatures[name] = pf
+	}
+
+	if ht := r.core.ResolvedTypes["VK_DEFINE_HANDLE"]; ht != nil {
+		// VK_NULL_HANDLE is a type in vk.xml, but vk-gen declares it as a value of the handle types
+		vr["VK_NULL_HANDLE"].Resolve(tr, vr)
+		ht.PushValue(vr["VK_NULL_HANDLE"])
+	}
+
+	if g.Strict && len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return r, nil
+}
+
+// checkFeature applies the name transform, if any, to a resolved feature, renames colliding identifiers and validates
+// it. Problems are logged unless Strict is set, and returned either way.
+func (g *Generator) checkFeature(f *feat.Feature, featureName string, base ...*feat.Feature) []error {
+	var problems []error
+	if g.NameTransform != nil {
+		for _, r := range f.ApplyNameTransform(g.NameTransform) {
+			problems = append(problems, r)
+			if !g.Strict {
+				logrus.WithField("feature", featureName).
+					WithField("registry name", r.RegistryName).
+					WithField("new name", r.GoName).
+					Warn("name transform would break the static files, keeping the default name")
+			}
+		}
+	}
+
+	for _, c := range f.RenameCollisions() {
+		problems = append(problems, fmt.Errorf("%s: Go identifier %s is already used by %s", c.RegistryName, c.GoName, c.CollidesWith))
+		if !g.Strict {
+			logrus.WithField("feature", featureName).
+				WithField("registry name", c.RegistryName).
+				WithField("collides with", c.CollidesWith).
+				WithField("new name", c.NewGoName).
+				Warnf("Go identifier %s is already in use, renaming", c.GoName)
+		}
+	}
+
+	for _, err := range f.Validate(base...) {
+		problems = append(problems, err)
+		if !g.Strict {
+			logrus.WithField("feature", featureName).
+				WithField("error", err).
+				Error("generated package may not build")
+		}
+	}
+	return problems
+}
+
+// readVideoRegistry loads the StdVideo types from VideoFile into the registries, replacing the placeholders from
+// exceptions.json. The codec constants are returned so they can be included in the core feature.
+func (g *Generator) readVideoRegistry(tr def.TypeRegistry, vr def.ValueRegistry) (*def.IncludeSet, error) {
+	f, err := os.Open(g.VideoFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not open Vulkan Video registry file: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := xmlquery.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse Vulkan Video registry XML: %w", err)
+	}
+	return def.ReadVideoRegistryFromXML(doc, tr, vr, g.API), nil
+}
+
+// DryRun resolves the selected features and writes a report of the types and values that Generate would write to w,
+// for the core feature and then each platform, without writing any files
+func (g *Generator) DryRun(w io.Writer) error {
+	r, err := g.resolve()
+	if err != nil {
+		return err
+	}
+
+	writeReport(w, r.core, "core")
+	for _, name := range r.platformNames {
+		writeReport(w, r.platformFeatures[name], name)
+	}
+	return nil
+}
+
+// Generate resolves the selected features and writes the generated package to outDir, which is created if needed
+func (g *Generator) Generate(outDir string) error {
+	if !token.IsIdentifier(g.PackageName) {
+		return fmt.Errorf("%s is not a valid Go package name", g.PackageName)
+	}
+	if err := os.MkdirAll(outDir, 0777); err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	r, err := g.resolve()
+	if err != nil {
+		return err
+	}
+	vr := r.vr
+
+	out := NewOutput(outDir)
+	out.PackageName, out.ImportPath = g.PackageName, g.ImportPath
+	out.Source, out.Revision = g.Source, g.registry.Revision()
+	out.KeepUnchanged = g.KeepUnchanged
+	if g.KeepUnchanged {
+		out.ReadPreviousOutput()
+	}
+	out.GoimportsPath = g.GoimportsPath
+	if out.GoimportsPath == "" {
+		if out.GoimportsPath, err = FindGoimports(); err != nil {
+			logrus.WithField("error", err).Error("Could not find goimports, files will not be formatted")
+		}
+	}
+
+	manifest := &feat.Manifest{}
+	manifest.Add(r.core)
+
+	commandCount := 0
+	var allCommands []def.TypeDefiner
+	for tc, fc := range r.core.FilterByCategory() {
+		out.WriteCategory(tc, fc, nil, 0)
+		if tc == def.CatCommand {
+			commandCount += len(fc.ResolvedTypes)
+			allCommands = append(allCommands, fc.SortedTypes()...)
+		}
+	}
+	out.WriteExtensionNames(r.core, nil)
+	out.WriteFeatureChain(r.core)
+	if g.StructSizeTest {
+		out.WriteStructSizeTest(r.core, vr)
+	}
+
+	for _, name := range r.platformNames {
+		pf, plat := r.platformFeatures[name], r.platforms[name]
+		manifest.Add(pf)
+		out.WriteExtensionNames(pf, plat)
+		for tc, fc := range pf.FilterByCategory() {
+			out.WriteCategory(tc, fc, plat, commandCount)
+			if tc == def.CatCommand {
+				commandCount += len(fc.ResolvedTypes)
+				allCommands = append(allCommands, fc.SortedTypes()...)
+			}
+		}
+	}
+
+	if g.CommandTable {
+		out.WriteCommandTable(allCommands)
+	}
+	if g.SymbolTable {
+		out.WriteSymbolTable(manifest)
+	}
+
+	manifest.Files, manifest.SourceHashes = out.Hashes, out.SourceHashes
+	if err := manifest.WriteFile(out.Path("manifest.json")); err != nil {
+		return err
+	}
+
+	if g.StaticDir != "" {
+		return out.CopyStaticFiles(g.StaticDir)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package gen
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/bbredesen/vk-gen/feat"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	logrus.SetLevel(logrus.FatalLevel)
+}
+
+const generatorFixture = `<registry>
+<types>
+	<type category="struct" name="VkExtent2D">
+		<member><type>uint32_t</type> <name>width</name></member>
+		<member><type>uint32_t</type> <name>height</name></member>
+	</type>
+	<type category="struct" name="VkOffset2D">
+		<member><type>int32_t</type> <name>x</name></member>
+		<member><type>int32_t</type> <name>y</name></member>
+	</type>
+	<type category="handle"><type>VK_DEFINE_HANDLE</type>(<name>VkDevice</name>)</type>
+	<type category="struct" name="VkExampleInfoEXT">
+		<member><type>VkExtent2D</type> <name>extent</name></member>
+	</type>
+</types>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkExtent2D"/>
+		<type name="VkOffset2D"/>
+		<type name="VkDevice"/>
+	</require>
+</feature>
+<extensions>
+	<extension name="VK_EXT_example" number="1" type="device" supported="vulkan">
+		<require>
+			<type name="VkExampleInfoEXT"/>
+		</require>
+	</extension>
+</extensions>
+</registry>`
+
+func newTestGenerator(t *testing.T, opts Options) *Generator {
+	t.Helper()
+	return newTestGeneratorFor(t, generatorFixture, opts)
+}
+
+func newTestGeneratorFor(t *testing.T, registryXML string, opts Options) *Generator {
+	t.Helper()
+
+	reg, err := feat.LoadRegistry(strings.NewReader(registryXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exceptionsBytes, err := os.ReadFile("../exceptions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Exceptions = gjson.ParseBytes(exceptionsBytes)
+	if opts.GoimportsPath == "" {
+		// Files are left unformatted, so the test does not depend on goimports
+		opts.GoimportsPath = "true"
+	}
+	return NewGenerator(reg, opts)
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           Options
+		setup          func(g *Generator)
+		files, missing []string
+		contains       map[string]string // file name to expected contents
+	}{
+		{
+			name:     "everything",
+			opts:     Options{StructSizeTest: true, SymbolTable: true, CommandTable: true},
+			files:    []string{"struct.go", "struct_size_test.go", "symbol_table.go", "command_table.go", "manifest.json"},
+			contains: map[string]string{"struct.go": "type ExampleInfoEXT struct", "symbol_table.go": `"VkOffset2D": "Offset2D",`},
+		},
+		{
+			name:     "optional files off",
+			files:    []string{"struct.go", "manifest.json"},
+			missing:  []string{"struct_size_test.go", "symbol_table.go", "command_table.go"},
+			contains: map[string]string{"struct.go": "type Offset2D struct"},
+		},
+		{
+			name: "only names",
+			opts: Options{SymbolTable: true, Platforms: []string{"win32"}},
+			setup: func(g *Generator) {
+				g.SelectNames("VkExampleInfoEXT")
+			},
+			missing:  []string{"struct_win32.go"},
+			contains: map[string]string{"symbol_table.go": `"VkExampleInfoEXT": "ExampleInfoEXT",`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGenerator(t, tt.opts)
+			if tt.setup != nil {
+				tt.setup(g)
+			}
+			outDir := t.TempDir()
+			if err := g.Generate(outDir); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, f := range tt.files {
+				if _, err := os.Stat(filepath.Join(outDir, f)); err != nil {
+					t.Errorf("%s was not written", f)
+				}
+			}
+			for _, f := range tt.missing {
+				if _, err := os.Stat(filepath.Join(outDir, f)); err == nil {
+					t.Errorf("%s should not be written", f)
+				}
+			}
+			for f, want := range tt.contains {
+				data, err := os.ReadFile(filepath.Join(outDir, f))
+				if err != nil {
+					t.Errorf("%s was not written", f)
+				} else if !strings.Contains(string(data), want) {
+					t.Errorf("want %q in %s:\n%s", want, f, data)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSelection(t *testing.T) {
+	g := newTestGenerator(t, Options{SymbolTable: true})
+	g.SelectVersionRange("", "1.0")
+	outDir := t.TempDir()
+	if err := g.Generate(outDir); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "symbol_table.go"))
+	if !strings.Contains(string(data), "VkExtent2D") || strings.Contains(string(data), "VkExampleInfoEXT") {
+		t.Errorf("extension struct generated without SelectAllExtensions:\n%s", data)
+	}
+
+	g = newTestGenerator(t, Options{SymbolTable: true})
+	g.SelectVersionRange("", "1.0")
+	g.SelectAllExtensions()
+	g.Exclude("VK_EXT_example")
+	outDir = t.TempDir()
+	if err := g.Generate(outDir); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(outDir, "symbol_table.go"))
+	if strings.Contains(string(data), "VkExampleInfoEXT") {
+		t.Errorf("excluded extension struct generated:\n%s", data)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"bad package name", Options{PackageName: "my-vk"}},
+		{"unknown platform", Options{Platforms: []string{"amiga"}}},
+		{"platform without a Go target", Options{Platforms: []string{"screen"}}},
+		{"missing video file", Options{VideoFile: "no-such-video.xml"}},
+		{"name transform breaking the static files", Options{Strict: true, NameTransform: func(vkName string, cat def.TypeCategory) string {
+			if vkName == "VkOffset2D" {
+				return "ChainNode"
+			}
+			return def.DefaultNameTransform(vkName, cat)
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := newTestGenerator(t, tt.opts).Generate(t.TempDir()); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
+
+func TestGenerateTwice(t *testing.T) {
+	g := newTestGenerator(t, Options{SymbolTable: true})
+	if err := g.DryRun(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	// The features are only resolved once, and each Generate writes the same files
+	var outputs []map[string]string
+	for i := 0; i < 2; i++ {
+		outDir := t.TempDir()
+		if err := g.Generate(outDir); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := os.ReadDir(outDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := make(map[string]string)
+		for _, e := range entries {
+			data, err := os.ReadFile(filepath.Join(outDir, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Skip the header, which has the time of generation
+			_, files[e.Name()], _ = strings.Cut(string(data), "\n")
+		}
+		outputs = append(outputs, files)
+	}
+
+	if !reflect.DeepEqual(outputs[0], outputs[1]) {
+		t.Errorf("second Generate wrote different files:\n%v\n%v", outputs[0], outputs[1])
+	}
+	if got := strings.Count(outputs[1]["handle.go"], "NULL_HANDLE handle ="); got != 1 {
+		t.Errorf("NULL_HANDLE declared %d times in\n%s", got, outputs[1]["handle.go"])
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	g := newTestGenerator(t, Options{})
+	g.SelectNames("VkExampleInfoEXT")
+
+	sb := &strings.Builder{}
+	if err := g.DryRun(sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"== core ==\n", "  ExampleInfoEXT (VkExampleInfoEXT)\n", "  Extent2D (VkExtent2D)\n"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("want %q in\n%s", want, sb.String())
+		}
+	}
+	if strings.Contains(sb.String(), "Offset2D") {
+		t.Errorf("report includes a name that was not selected:\n%s", sb.String())
+	}
+}
+
+const imageTilingFixture = `<registry>
+<types>
+	<type name="VkImageTiling" category="enum"/>
+</types>
+<enums name="VkImageTiling" type="enum">
+	<enum value="0" name="VK_IMAGE_TILING_OPTIMAL"/>
+	<enum value="1" name="VK_IMAGE_TILING_LINEAR"/>
+</enums>
+<feature api="vulkan" name="VK_VERSION_1_0" number="1.0">
+	<require>
+		<type name="VkImageTiling"/>
+	</require>
+</feature>
+</registry>`
+
+func TestOptionsPerGenerator(t *testing.T) {
+	// Each Generator has its own registry, so the names and print options set by one must not leak into the next. The
+	// cases run in order, each default after one that changed the output.
+	tests := []struct {
+		name      string
+		opts      Options
+		want, not string
+	}{
+		{"short names", Options{ShortEnumNames: true}, "\nOPTIMAL ImageTiling = 0", "IMAGE_TILING_OPTIMAL ImageTiling"},
+		{"default after short names", Options{}, "\nIMAGE_TILING_OPTIMAL ImageTiling = 0", "\nOPTIMAL ImageTiling"},
+		{"name transform", Options{NameTransform: func(vkName string, cat def.TypeCategory) string {
+			return "Vk" + def.DefaultNameTransform(vkName, cat)
+		}}, "\nVkIMAGE_TILING_OPTIMAL VkImageTiling = 0", "\nIMAGE_TILING_OPTIMAL"},
+		{"default after name transform", Options{}, "\nIMAGE_TILING_OPTIMAL ImageTiling = 0", "VkIMAGE_TILING_OPTIMAL"},
+		{"untyped enums", Options{UntypedEnums: true}, "\nIMAGE_TILING_OPTIMAL = 0", "IMAGE_TILING_OPTIMAL ImageTiling"},
+		{"default after untyped enums", Options{}, "\nIMAGE_TILING_OPTIMAL ImageTiling = 0", "IMAGE_TILING_OPTIMAL = 0"},
+	}
+	for _, tt := range tests {
+		outDir := t.TempDir()
+		if err := newTestGeneratorFor(t, imageTilingFixture, tt.opts).Generate(outDir); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(outDir, "enum.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), tt.want) || strings.Contains(string(data), tt.not) {
+			t.Errorf("%s: want %q and not %q in\n%s", tt.name, tt.want, tt.not, data)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/bbredesen/vk-gen/feat"
+	"github.com/sirupsen/logrus"
+)
+
+// Output writes generated files into one directory. Every file gets the same header and package clause, and is run
+// through goimports once it is written.
+type Output struct {
+	Dir         string
+	PackageName string
+	// ImportPath, if set, is written as an import comment on each package clause
+	ImportPath string
+	// Source and Revision describe the registry in the header comment, see feat.Registry.Revision
+	Source, Revision string
+	// GoimportsPath is the goimports binary to format files with; files are left unformatted if it is empty
+	GoimportsPath string
+	// KeepUnchanged leaves a file untouched if the previous run wrote the same content, not counting the header
+	// comment, which has the generation time. Files are written next to their final path by Create and only moved into
+	// place, and formatted, by Finish if their content hash differs from the manifest of the previous run. A file that
+	// has no hash in the previous manifest is formatted and then restored if it matches the previous file.
+	// ReadPreviousOutput must be called before any file is written.
+	KeepUnchanged bool
+
+	// Hashes is the content hash of each generated file, by file name, for the manifest. SourceHashes is the hash of
+	// each file before goimports, see feat.Manifest.
+	Hashes, SourceHashes map[string]string
+
+	previous       map[string][]byte
+	previousSource map[string]string
+}
+
+// NewOutput returns an Output writing package vk into dir
+func NewOutput(dir string) *Output {
+	return &Output{
+		Dir:          dir,
+		PackageName:  "vk",
+		Hashes:       make(map[string]string),
+		SourceHashes: make(map[string]string),
+		previous:     make(map[string][]byte),
+	}
+}
+
+// Path returns the path of the named file in the output directory
+func (o *Output) Path(filename string) string { return filepath.Join(o.Dir, filename) }
+
+// ReadPreviousOutput snapshots the .go files that are in the output directory before generation, along with the
+// source hashes in its manifest.json, see KeepUnchanged
+func (o *Output) ReadPreviousOutput() {
+	paths, _ := filepath.Glob(filepath.Join(o.Dir, "*.go"))
+	for _, p := range paths {
+		if data, err := os.ReadFile(p); err == nil {
+			o.previous[p] = data
+		}
+	}
+	if m, err := feat.ReadManifestFile(o.Path("manifest.json")); err == nil {
+		o.previousSource = m.SourceHashes
+	}
+}
+
+// stagingSuffix is appended to the path of a file written with KeepUnchanged until Finish moves it into place
+const stagingSuffix = ".new"
+
+// Create creates a file to be generated at outpath, which must be passed to Finish once the file is closed. With
+// KeepUnchanged, the file is created beside outpath instead.
+func (o *Output) Create(outpath string) (*os.File, error) {
+	if o.KeepUnchanged {
+		return os.Create(outpath + stagingSuffix)
+	}
+	return os.Create(outpath)
+}
+
+const fileHeader string = "// Code generated by vk-gen from %s at %s. DO NOT EDIT.\n" // fix doc/issue-1
+
+// PrintFileHeader writes the generated code comment, with the revision of the registry it was generated from, and the
+// package clause.
+func (o *Output) PrintFileHeader(w io.Writer) {
+	fmt.Fprintf(w, fileHeader, o.Source, time.Now())
+	if o.Revision != "" {
+		fmt.Fprintf(w, "// Registry revision: %s\n", o.Revision)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, o.PackageClause())
+	fmt.Fprintln(w)
+}
+
+// PackageClause returns the package clause for generated and static files, with an import comment if ImportPath is set
+func (o *Output) PackageClause() string {
+	if o.ImportPath == "" {
+		return "package " + o.PackageName
+	}
+	return fmt.Sprintf("package %s // import %q", o.PackageName, o.ImportPath)
+}
+
+// rxPackageClause matches the package clause of the static files, which are written as package vk
+var rxPackageClause = regexp.MustCompile(`(?m)^package vk$`)
+
+// WriteCategory writes the types and values of one category of fc to a file named for the category, e.g. struct.go,
+// or struct_win32.go for a platform. startingCount offsets the global declarations numbered per type, so the
+// platform files continue from the core files.
+func (o *Output) WriteCategory(tc def.TypeCategory, fc *feat.Feature, platform *feat.Platform, startingCount int) {
+	if tc == def.CatInclude {
+		return
+	}
+
+	reg := fc.ResolvedTypes
+
+	if len(reg) == 0 && len(fc.ResolvedValues) == 0 {
+		return
+	}
+
+	filename := strings.ToLower(strings.TrimPrefix(tc.String(), "Cat"))
+	if platform != nil {
+		filename = filename + "_" + platform.FileSuffix()
+	}
+
+	outpath := o.Path(filename + ".go")
+
+	f, _ := o.Create(outpath)
+	// explicit f.Close() below; not deferred because the file must be written to disk before goimports is run
+
+	if platform != nil && platform.GoBuildTag != "" && tc != def.CatEnum && tc != def.CatBitmask {
+		fmt.Fprintf(f, "//go:build %s\n", platform.GoBuildTag)
+	}
+
+	o.PrintFileHeader(f)
+
+	// Command files need CGO import for direct C.Trampoline* calls
+	// This must come before other imports and has special format
+	if tc == def.CatCommand {
+		fmt.Fprintf(f, "// #include \"dlload.h\"\nimport \"C\"\n\n")
+	}
+
+	if platform != nil && len(platform.GoImports) > 0 {
+		fmt.Fprintf(f, "import (\n")
+		for _, i := range platform.GoImports {
+			fmt.Fprintf(f, "\"%s\"\n", i)
+		}
+		fmt.Fprintf(f, ")\n")
+	}
+
+	types := make([]def.TypeDefiner, 0, len(reg))
+	for _, v := range reg.Sorted() {
+		types = append(types, v)
+		v.AppendValues(fc.ResolvedValues[v.RegistryName()])
+		delete(fc.ResolvedValues, v.RegistryName())
+	}
+
+	sort.Sort(def.ByName(types))
+	def.WriteStringerCommands(f, types, tc, filename)
+
+	importMap := make(def.ImportMap)
+	for _, t := range types {
+		t.RegisterImports(importMap)
+	}
+	if len(importMap) > 0 {
+		keys := importMap.SortedKeys()
+		fmt.Fprint(f, "import (\n")
+		for _, k := range keys {
+			fmt.Fprintf(f, "  \"%s\"\n", k)
+		}
+		fmt.Fprintln(f, ")")
+		fmt.Fprintln(f)
+	}
+
+	printTypes(f, types, fc.ResolvedValues, startingCount)
+	printLooseValues(f, fc.ResolvedValues)
+
+	f.Close()
+
+	o.Finish(outpath)
+}
+
+// WriteExtensionNames writes extensions.go, listing the extensions generated into the package by the type of object
+// they must be enabled on. Platform files append their own extensions to the same slices from init().
+func (o *Output) WriteExtensionNames(f *feat.Feature, platform *feat.Platform) {
+	instExts, devExts := f.RequiredInstanceExtensions(), f.RequiredDeviceExtensions()
+	if platform != nil && len(instExts) == 0 && len(devExts) == 0 {
+		return
+	}
+
+	filename := "extensions"
+	if platform != nil {
+		filename = filename + "_" + platform.FileSuffix()
+	}
+	outpath := o.Path(filename + ".go")
+
+	w, _ := o.Create(outpath)
+
+	if platform != nil && platform.GoBuildTag != "" {
+		fmt.Fprintf(w, "//go:build %s\n", platform.GoBuildTag)
+	}
+	o.PrintFileHeader(w)
+
+	writeNames := func(names []string) {
+		for _, n := range names {
+			fmt.Fprintf(w, "\t\"%s\",\n", n)
+		}
+	}
+
+	if platform == nil {
+		fmt.Fprint(w, "// RequiredInstanceExtensions lists the generated extensions that are enabled through InstanceCreateInfo\n")
+		fmt.Fprint(w, "var RequiredInstanceExtensions = []string{\n")
+		writeNames(instExts)
+		fmt.Fprint(w, "}\n\n")
+		fmt.Fprint(w, "// RequiredDeviceExtensions lists the generated extensions that are enabled through DeviceCreateInfo\n")
+		fmt.Fprint(w, "var RequiredDeviceExtensions = []string{\n")
+		writeNames(devExts)
+		fmt.Fprint(w, "}\n")
+	} else {
+		fmt.Fprint(w, "func init() {\n")
+		if len(instExts) > 0 {
+			fmt.Fprint(w, "RequiredInstanceExtensions = append(RequiredInstanceExtensions,\n")
+			writeNames(instExts)
+			fmt.Fprint(w, ")\n")
+		}
+		if len(devExts) > 0 {
+			fmt.Fprint(w, "RequiredDeviceExtensions = append(RequiredDeviceExtensions,\n")
+			writeNames(devExts)
+			fmt.Fprint(w, ")\n")
+		}
+		fmt.Fprint(w, "}\n")
+	}
+
+	w.Close()
+	o.Finish(outpath)
+}
+
+// WriteFeatureChain writes feature_chain.go, with FeatureChain and NewFeatureChain for the features structs of f, see
+// def.WriteFeatureChain. Platform structs are left out, since they would need the platform's build tag.
+func (o *Output) WriteFeatureChain(f *feat.Feature) {
+	outpath := o.Path("feature_chain.go")
+	w, err := o.Create(outpath)
+	if err != nil {
+		logrus.WithField("path", outpath).
+			WithField("error", err).
+			Error("Could not create feature chain file")
+		return
+	}
+
+	o.PrintFileHeader(w)
+	def.WriteFeatureChain(w, f.SortedTypes(), f.OriginOf)
+	w.Close()
+
+	o.Finish(outpath)
+}
+
+// WriteStructSizeTest writes struct_size_test.go, checking the size and member offsets of each struct and union of f
+// against its C layout, see def.WriteStructSizeTest. Platform structs are left out, since they would need the platform's
+// build tag.
+func (o *Output) WriteStructSizeTest(f *feat.Feature, vr def.ValueRegistry) {
+	outpath := o.Path("struct_size_test.go")
+	w, err := o.Create(outpath)
+	if err != nil {
+		logrus.WithField("path", outpath).
+			WithField("error", err).
+			Error("Could not create struct size test file")
+		return
+	}
+
+	// Sizes are computed for a 64-bit target
+	fmt.Fprint(w, "//go:build amd64 || arm64\n\n")
+	o.PrintFileHeader(w)
+	fmt.Fprint(w, "import (\n\"testing\"\n\"unsafe\"\n)\n\n")
+	def.WriteStructSizeTest(w, f.SortedTypes(), vr)
+	w.Close()
+
+	o.Finish(outpath)
+}
+
+// WriteCommandTable writes command_table.go, see def.WriteCommandTable. Function pointers are stored as unsafe.Pointer,
+// so commands from every platform can share one file without build tags.
+func (o *Output) WriteCommandTable(commands []def.TypeDefiner) {
+	sort.Sort(def.ByName(commands))
+
+	outpath := o.Path("command_table.go")
+	w, err := o.Create(outpath)
+	if err != nil {
+		logrus.WithField("path", outpath).
+			WithField("error", err).
+			Error("Could not create command table file")
+		return
+	}
+
+	o.PrintFileHeader(w)
+	fmt.Fprint(w, "import \"unsafe\"\n\n")
+	def.WriteCommandTable(w, commands)
+	w.Close()
+
+	o.Finish(outpath)
+}
+
+// WriteSymbolTable writes symbol_table.go, mapping the Vulkan name of each exported type, command and value in m to its
+// Go identifier. The map only holds strings, so symbols from every platform share one file without build tags.
+func (o *Output) WriteSymbolTable(m *feat.Manifest) {
+	outpath := o.Path("symbol_table.go")
+	w, err := o.Create(outpath)
+	if err != nil {
+		logrus.WithField("path", outpath).
+			WithField("error", err).
+			Error("Could not create symbol table file")
+		return
+	}
+
+	o.PrintFileHeader(w)
+	fmt.Fprint(w, "var goSymbols = map[string]string{\n")
+	for _, entries := range [][]feat.ManifestEntry{m.Types, m.Values} {
+		for _, e := range entries {
+			if e.Name == "" || !unicode.IsUpper([]rune(e.Name)[0]) {
+				continue
+			}
+			fmt.Fprintf(w, "\"%s\": \"%s\",\n", e.RegistryName, e.Name)
+		}
+	}
+	fmt.Fprint(w, "}\n\n")
+	fmt.Fprint(w, "// GoSymbolFor returns the Go identifier generated for a Vulkan type, command or value name, e.g.\n")
+	fmt.Fprint(w, "// \"VkInstanceCreateInfo\" gives \"InstanceCreateInfo\". ok is false if the name was not generated.\n")
+	fmt.Fprint(w, "func GoSymbolFor(vkName string) (goName string, ok bool) {\n")
+	fmt.Fprint(w, "goName, ok = goSymbols[vkName]\nreturn\n}\n")
+	w.Close()
+
+	o.Finish(outpath)
+}
+
+// Finish runs goimports on a file that has been written and closed, and records its content hash
+func (o *Output) Finish(outpath string) {
+	if o.KeepUnchanged && o.skipUnchanged(outpath) {
+		return
+	}
+	if data, err := os.ReadFile(outpath); err == nil {
+		o.SourceHashes[filepath.Base(outpath)] = contentHash(data)
+	}
+
+	if o.GoimportsPath != "" {
+		logrus.WithField("file", filepath.Base(outpath)).Info("Running goimports")
+
+		cmd := exec.Command(o.GoimportsPath, "-w", outpath)
+		e := &strings.Builder{}
+		cmd.Stderr = e
+
+		goimpErr := cmd.Run()
+		if goimpErr != nil {
+			logrus.
+				WithField("path", outpath).
+				WithField("error", goimpErr.Error()).
+				WithField("goimports output", e.String()).
+				Error("Failed to format source file")
+		}
+	}
+
+	o.finishOutputFile(outpath)
+}
+
+// skipUnchanged removes a file written by Create, leaving the previous file in place, if its content is the same as
+// the source of the previous file. Otherwise, it moves the new file to outpath. It returns true if the file was
+// skipped.
+func (o *Output) skipUnchanged(outpath string) bool {
+	staged, name := outpath+stagingSuffix, filepath.Base(outpath)
+	data, err := os.ReadFile(staged)
+	if err != nil {
+		return false
+	}
+
+	if old, found := o.previous[outpath]; found && o.previousSource[name] == contentHash(data) {
+		os.Remove(staged)
+		o.SourceHashes[name] = o.previousSource[name]
+		o.Hashes[name] = contentHash(old)
+		logrus.WithField("file", name).Info("File is unchanged, skipping it")
+		return true
+	}
+
+	if err := os.Rename(staged, outpath); err != nil {
+		logrus.WithField("path", outpath).
+			WithField("error", err).
+			Error("Could not move generated file into place")
+	}
+	return false
+}
+
+// finishOutputFile records the content hash of a generated file. With KeepUnchanged, a file that only differs from the
+// file it replaced in the header comment is restored to the previous version.
+func (o *Output) finishOutputFile(outpath string) {
+	data, err := os.ReadFile(outpath)
+	if err != nil {
+		return
+	}
+	hash := contentHash(data)
+	o.Hashes[filepath.Base(outpath)] = hash
+
+	if !o.KeepUnchanged {
+		return
+	}
+	if old, found := o.previous[outpath]; found && contentHash(old) == hash {
+		if err := os.WriteFile(outpath, old, 0666); err != nil {
+			logrus.WithField("path", outpath).
+				WithField("error", err).
+				Error("Could not restore unchanged file")
+			return
+		}
+		logrus.WithField("file", filepath.Base(outpath)).Info("File is unchanged, keeping previous version")
+	}
+}
+
+// contentHash returns a hex encoded SHA-256 of data, ignoring the header comment lines written by PrintFileHeader
+func contentHash(data []byte) string {
+	h := sha256.New()
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(line, "// Code generated by ") || strings.HasPrefix(line, "// Registry revision: ") {
+			continue
+		}
+		io.WriteString(h, line)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func printTypes(w io.Writer, types []def.TypeDefiner, vals map[string]def.ValueRegistry, globalOffset int) {
+	globalBuf := &strings.Builder{}
+	initBuf := &strings.Builder{}
+	contentBuf := &strings.Builder{}
+
+	for i, v := range types {
+		if strings.HasPrefix(v.PublicName(), "!") {
+			continue
+		}
+
+		v.PrintGlobalDeclarations(globalBuf, i+globalOffset, i == 0)
+
+		v.PrintPublicDeclaration(contentBuf)
+		v.PrintInternalDeclaration(contentBuf)
+
+		v.PrintFileInitContent(initBuf) // Intentionally called after public declaration, which may do some processing needed for file init()
+	}
+
+	if globalBuf.Len() > 0 {
+		fmt.Fprintf(w, "const (\n")
+		fmt.Fprint(w, globalBuf.String())
+		fmt.Fprintf(w, ")\n\n")
+	}
+
+	if initBuf.Len() > 0 {
+		fmt.Fprint(w, "func init() {\n")
+		fmt.Fprint(w, initBuf.String())
+		fmt.Fprint(w, "}\n\n")
+	}
+
+	fmt.Fprint(w, contentBuf.String())
+
+}
+
+func printLooseValues(w io.Writer, valsByTypeName map[string]def.ValueRegistry) {
+	// sort and refactored for cleanup/issue-3
+
+	typeNames := make([]string, 0, len(valsByTypeName))
+	for k := range valsByTypeName {
+		typeNames = append(typeNames, k)
+	}
+	sort.Strings(typeNames)
+
+	for _, k := range typeNames {
+		vr := valsByTypeName[k]
+		// Values will be sorted by const name for extension names/spec versions, and by value for typed consts
+		allValues := make([]def.ValueDefiner, 0, len(vr))
+		for _, val := range vr {
+			allValues = append(allValues, val)
+		}
+
+		if k == "" {
+			fmt.Fprint(w, "// Extension names and versions\n")
+			// Drop the values into a slice and sort by the const name
+			sort.Sort(def.ByValuePublicName(allValues))
+		} else {
+			fmt.Fprintf(w, "// Platform-specific values for %s\n", k)
+			sort.Sort(def.ByValue(allValues))
+		}
+
+		fmt.Fprintf(w, "const (\n")
+
+		for _, val := range allValues {
+			val.PrintPublicDeclaration(w)
+		}
+		fmt.Fprintf(w, ")\n\n")
+	}
+}
+
+// CopyStaticFiles copies the hand written files in source (static_include) into the output directory, rewriting the
+// package clause of each .go file.
+func (o *Output) CopyStaticFiles(source string) error {
+	logrus.Info("Copying static files")
+
+	// Naive solution from https://stackoverflow.com/questions/51779243/copy-a-folder-in-go
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		var relPath string = strings.Replace(path, source, "", 1)
+		if relPath == "" {
+			return nil
+		}
+		if info.IsDir() {
+			return os.Mkdir(filepath.Join(o.Dir, relPath), 0777)
+		} else {
+			var data, err1 = os.ReadFile(filepath.Join(source, relPath))
+			if err1 != nil {
+				return err1
+			}
+			if filepath.Ext(relPath) == ".go" {
+				data = rxPackageClause.ReplaceAll(data, []byte(o.PackageClause()))
+			}
+			return os.WriteFile(filepath.Join(o.Dir, relPath), data, 0666)
+		}
+	})
+}
+
+// FindGoimports looks for the goimports binary on PATH and in the bin directory of each GOPATH entry
+func FindGoimports() (path string, err error) {
+	// goimports is probably in GOPATH which may not be in the user's PATH
+	goPath := os.Getenv("GOPATH")
+	if goPath == "" {
+		// If GOPATH is not set, use go's default
+		goPath = build.Default.GOPATH
+	}
+
+	// There may be multiple paths, so split and add "/bin" to each
+	paths := strings.Split(goPath, string(os.PathListSeparator))
+	goPath = ""
+	for _, path := range paths {
+		goPath += fmt.Sprintf("%s%sbin%s", path, string(os.PathSeparator), string(os.PathListSeparator))
+	}
+
+	// Add PATH paths to the end
+	goPath += os.Getenv("PATH")
+
+	os.Setenv("PATH", goPath)
+
+	return exec.LookPath("goimports")
+}
@@ -0,0 +1,74 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeepUnchanged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the goimports stand-in is a shell script")
+	}
+
+	// goimports is replaced by a script recording the files it is asked to format
+	dir := t.TempDir()
+	formatted := filepath.Join(dir, "formatted.txt")
+	goimports := filepath.Join(dir, "goimports")
+	script := "#!/bin/sh\nbasename \"$2\" >> " + formatted + "\n"
+	if err := os.WriteFile(goimports, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "vk")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	runs := []struct {
+		name          string
+		registryXML   string
+		wantFormatted []string
+		wantTouched   []string
+	}{
+		{"first run", generatorFixture, []string{"extensions.go", "external.go", "feature_chain.go", "handle.go", "struct.go"}, []string{"extensions.go", "external.go", "feature_chain.go", "handle.go", "struct.go"}},
+		{"same registry", generatorFixture, nil, nil},
+		{"changed struct", strings.Replace(generatorFixture, `<name>y</name></member>`, `<name>y</name></member>
+		<member><type>int32_t</type> <name>z</name></member>`, 1), []string{"struct.go"}, []string{"struct.go"}},
+	}
+
+	for _, run := range runs {
+		os.Remove(formatted)
+		paths, _ := filepath.Glob(filepath.Join(outDir, "*.go"))
+		for _, p := range paths {
+			os.Chtimes(p, old, old)
+		}
+
+		g := newTestGeneratorFor(t, run.registryXML, Options{KeepUnchanged: true, GoimportsPath: goimports})
+		if err := g.Generate(outDir); err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := os.ReadFile(formatted)
+		gotFormatted := strings.Fields(string(data))
+		sort.Strings(gotFormatted)
+		if strings.Join(gotFormatted, ",") != strings.Join(run.wantFormatted, ",") {
+			t.Errorf("%s: formatted %v, want %v", run.name, gotFormatted, run.wantFormatted)
+		}
+
+		var gotTouched []string
+		paths, _ = filepath.Glob(filepath.Join(outDir, "*"))
+		for _, p := range paths {
+			if strings.HasSuffix(p, stagingSuffix) {
+				t.Errorf("%s: %s was left behind", run.name, filepath.Base(p))
+			}
+			if info, err := os.Stat(p); err == nil && filepath.Ext(p) == ".go" && info.ModTime().After(old) {
+				gotTouched = append(gotTouched, filepath.Base(p))
+			}
+		}
+		if strings.Join(gotTouched, ",") != strings.Join(run.wantTouched, ",") {
+			t.Errorf("%s: rewrote %v, want %v", run.name, gotTouched, run.wantTouched)
+		}
+	}
+}
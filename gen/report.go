@@ -0,0 +1,46 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bbredesen/vk-gen/def"
+	"github.com/bbredesen/vk-gen/feat"
+)
+
+// writeReport writes the resolved types of a feature to w, grouped by category and sorted by name, with the number of
+// values generated for each type. It is the output of DryRun.
+func writeReport(w io.Writer, f *feat.Feature, featureName string) {
+	fmt.Fprintf(w, "== %s ==\n", featureName)
+
+	byCat := f.FilterByCategory()
+	for tc := def.CatNone; tc < def.CatMaximum; tc++ {
+		fc := byCat[tc]
+		if fc == nil || len(fc.ResolvedTypes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s: %d types\n", strings.TrimPrefix(tc.String(), "Cat"), len(fc.ResolvedTypes))
+		for _, td := range fc.SortedTypes() {
+			fmt.Fprintf(w, "  %s (%s)", td.PublicName(), td.RegistryName())
+			if n := len(f.ResolvedValues[td.RegistryName()]); n > 0 {
+				fmt.Fprintf(w, ", %d values", n)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if n := len(f.ResolvedValues[""]); n > 0 {
+		fmt.Fprintf(w, "Untyped values: %d\n", n)
+	}
+
+	stats := f.Stats()
+	var counts []string
+	for tc := def.CatNone; tc < def.CatMaximum; tc++ {
+		if n := stats[tc]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, strings.ToLower(strings.TrimPrefix(tc.String(), "Cat"))))
+		}
+	}
+	fmt.Fprintf(w, "Total: %d types (%s), %d values\n", len(f.ResolvedTypes), strings.Join(counts, ", "), f.ValueCount())
+	fmt.Fprintln(w)
+}
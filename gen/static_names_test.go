@@ -0,0 +1,82 @@
+package gen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bbredesen/vk-gen/def"
+)
+
+// TestStaticNames checks def.StaticRegistryNames and def.StaticIdentifiers against static_include, so that
+// ApplyNameTransform keeps protecting every name the static files use or declare
+func TestStaticNames(t *testing.T) {
+	fset := token.NewFileSet()
+	paths, _ := filepath.Glob("../static_include/*.go")
+	var files []*ast.File
+	declared := make(map[string]bool)
+	for _, p := range paths {
+		src, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, p, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+		for name, obj := range f.Scope.Objects {
+			if obj.Kind != ast.Bad {
+				declared[name] = true
+			}
+		}
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil {
+				declared[fd.Name.Name] = true
+			}
+		}
+	}
+
+	// The generated types and values are the identifiers the static files use without declaring
+	undefined := make(map[string]bool)
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if msg := err.(types.Error).Msg; strings.HasPrefix(msg, "undefined: ") {
+				undefined[strings.TrimPrefix(msg, "undefined: ")] = true
+			}
+		},
+	}
+	conf.Check("vk", fset, files, nil)
+
+	var wantUsed []string
+	for n := range def.StaticRegistryNames {
+		wantUsed = append(wantUsed, def.DefaultNameTransform(n, def.CatNone))
+	}
+	compareNames(t, "used by the static files", keys(undefined), wantUsed)
+	compareNames(t, "declared by the static files", keys(declared), keys(def.StaticIdentifiers))
+}
+
+func keys(m map[string]bool) []string {
+	var rval []string
+	for k := range m {
+		rval = append(rval, k)
+	}
+	return rval
+}
+
+func compareNames(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("names %s:\ngot  %v\nwant %v", what, got, want)
+	}
+}
@@ -13,3 +13,9 @@ func translateInternal_Bool32(val bool) Bool32 {
 		return Bool32(FALSE)
 	}
 }
+
+// ToGo returns the Go bool for a Bool32 returned by Vulkan. Any non-zero value is true, as in C.
+func (b Bool32) ToGo() bool { return translatePublic_Bool32(b) }
+
+// FromBool returns TRUE or FALSE as a Bool32, for code that works with the internal structs directly
+func FromBool(v bool) Bool32 { return translateInternal_Bool32(v) }
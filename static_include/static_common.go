@@ -2,6 +2,7 @@ package vk
 
 import (
 	"bytes"
+	"reflect"
 	"runtime"
 	"unsafe"
 )
@@ -21,6 +22,57 @@ type Goifier interface {
 	Goify() Vulkanizer
 }
 
+// ChainNode is implemented by every struct that can extend another struct through its PNext member.
+type ChainNode interface {
+	StructureType() StructureType
+	NextPtr() *unsafe.Pointer
+	vulkanizeChainNode() unsafe.Pointer
+}
+
+// chainHeader matches the layout of the first two members of every Vulkan struct with sType and pNext members, i.e.
+// VkBaseOutStructure.
+type chainHeader struct {
+	sType StructureType
+	pNext unsafe.Pointer
+}
+
+// AppendNext Vulkanizes ext and links it at the end of base's pNext chain. Since ext is Vulkanized immediately, ext must
+// be fully populated (including its own pNext chain, if any) before calling AppendNext.
+//
+//	instanceCI := vk.InstanceCreateInfo{...}
+//	vk.AppendNext(&instanceCI, &vk.ValidationFeaturesEXT{...})
+func AppendNext(base, ext ChainNode) {
+	p := base.NextPtr()
+	for *p != nil {
+		p = &(*chainHeader)(*p).pNext
+	}
+	*p = ext.vulkanizeChainNode()
+}
+
+// LinkChain appends each of nodes to base's pNext chain in order, see AppendNext.
+//
+//	vk.LinkChain(&deviceCI, &indexingFeatures, &rayQueryFeatures)
+func LinkChain(base ChainNode, nodes ...ChainNode) {
+	for _, n := range nodes {
+		AppendNext(base, n)
+	}
+}
+
+// structureTypeOf maps each struct type with a fixed sType to that value. Entries are added by init() in the generated
+// struct files.
+var structureTypeOf = map[reflect.Type]StructureType{}
+
+// StructureTypeOf returns the sType value for v, which must be a struct or a pointer to a struct. The boolean is
+// false if the struct does not have an sType member.
+func StructureTypeOf(v any) (StructureType, bool) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	st, ok := structureTypeOf[t]
+	return st, ok
+}
+
 // max is an internal utility function, used in processing struct member slice/array lengths
 func max(nums ...int) int {
 	rval := 0
@@ -32,12 +84,6 @@ func max(nums ...int) int {
 	return rval
 }
 
-// Error implements the error interface
-// TODO: A way for commands to indicate if the Result code is an error for that command, or an unexpected return value?
-func (r Result) Error() string {
-	return r.String()
-}
-
 type vkCommand struct {
 	protoName string
 	argCount  int
@@ -160,7 +206,10 @@ func stringToNullTermBytes(s string) *byte {
 	return &b[0]
 }
 
+// nullTermBytesToString reads a fixed length char array. The whole array is used if it has no null terminator.
 func nullTermBytesToString(b []byte) string {
-	n := bytes.IndexByte(b, 0)
-	return string(b[:n])
+	if n := bytes.IndexByte(b, 0); n >= 0 {
+		b = b[:n]
+	}
+	return string(b)
 }
@@ -20,6 +20,7 @@ func makeVersion(major, minor, patch uint32) uint32 {
 	return major<<22 | minor<<12 | patch
 }
 
+// API versions are packed as variant in bits 29-31, major in bits 22-28, minor in bits 12-21 and patch in bits 0-11
 func makeApiVersion(variant, major, minor, patch uint32) uint32 {
 	return variant<<29 | major<<22 | minor<<12 | patch
 }
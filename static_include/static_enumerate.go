@@ -0,0 +1,33 @@
+package vk
+
+// Enumerate runs the two-call pattern used by Vulkan's enumeration commands: fn is called with a nil data pointer to
+// get the count, then again with room for that many elements. If the count grows between the two calls, Vulkan returns
+// INCOMPLETE and Enumerate starts over with the new count. The returned error is nil on success, or the Result of the
+// first failing call.
+//
+// Generated commands already wrap the pattern for their own outputs. Enumerate is for calling a command pointer
+// directly, e.g. one loaded through a CommandTable, where fn adapts the call to the count and data pointers:
+//
+//	devices, err := vk.Enumerate(func(count *uint32, data *vk.PhysicalDevice) vk.Result { ... })
+func Enumerate[T any](fn func(count *uint32, data *T) Result) ([]T, error) {
+	for {
+		var count uint32
+		if r := fn(&count, nil); r != Result(0) {
+			return nil, r
+		}
+		if count == 0 {
+			return nil, SUCCESS
+		}
+
+		data := make([]T, count)
+		r := fn(&count, &data[0])
+		if r == INCOMPLETE {
+			continue
+		}
+		if r != Result(0) {
+			return nil, r
+		}
+		// Vulkan may also write fewer elements than it first reported
+		return data[:count], SUCCESS
+	}
+}
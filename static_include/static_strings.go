@@ -0,0 +1,54 @@
+package vk
+
+import "unsafe"
+
+// Public structs expose char* members as Go strings, and Vulkanize converts them. These helpers are for code that works
+// with the internal pointers directly, e.g. in a pNext chain or a callback.
+
+// CString returns a pointer to a null-terminated copy of s. The copy is owned by the Go runtime, so there is nothing to
+// free, but the pointer must stay reachable for as long as Vulkan may read from it.
+func CString(s string) *byte {
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return &b[0]
+}
+
+// CStringFromBytes returns a pointer to the first byte of b without copying it. b must contain a null terminator,
+// otherwise this panics. Use it to avoid an allocation when the caller already holds a null-terminated buffer.
+func CStringFromBytes(b []byte) *byte {
+	for _, c := range b {
+		if c == 0 {
+			return &b[0]
+		}
+	}
+	panic("vk: CStringFromBytes called with a slice that is not null-terminated")
+}
+
+// GoString copies the null-terminated string at p into a Go string. A nil pointer returns an empty string.
+func GoString(p *byte) string {
+	if p == nil {
+		return ""
+	}
+	n := 0
+	for *(*byte)(unsafe.Add(unsafe.Pointer(p), n)) != 0 {
+		n++
+	}
+	return string(unsafe.Slice(p, n))
+}
+
+// GoStringN is GoString, but reads at most maxLen bytes if no null terminator is found first. Use it for pointers into
+// a buffer of known size.
+func GoStringN(p *byte, maxLen int) string {
+	if p == nil {
+		return ""
+	}
+	n := 0
+	for n < maxLen && *(*byte)(unsafe.Add(unsafe.Pointer(p), n)) != 0 {
+		n++
+	}
+	return string(unsafe.Slice(p, n))
+}
+
+// GoStringFromArray reads a fixed length char array, like VkPhysicalDeviceProperties.deviceName in the internal
+// structs, into a Go string. It stops at the first null byte, or uses the whole array if there is none.
+func GoStringFromArray(b []byte) string { return nullTermBytesToString(b) }